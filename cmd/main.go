@@ -3,6 +3,7 @@ package main
 import (
 	"bot/internal/app/helpers"
 	"bot/internal/app/logger"
+	"bot/internal/app/marketplace"
 	"bot/internal/pkg/app"
 	"log"
 	"os"
@@ -56,20 +57,55 @@ func runConsoleApp() {
 }
 
 func runBotApp() {
-	token := os.Getenv("TELEGRAM_BOT_TOKEN")
-
 	logger := logger.NewFileLogger("app.log", false)
 
-	scraperTimeoutInSeconds, err := strconv.Atoi(os.Getenv("SCRAPER_TIMEOUT_IN_SECONDS"))
-	if err != nil {
-		scraperTimeoutInSeconds = 60
+	config := app.TelegramBotAppConfig{
+		Token:                            os.Getenv("TELEGRAM_BOT_TOKEN"),
+		ScraperTimeoutInSeconds:          getEnvInt("SCRAPER_TIMEOUT_IN_SECONDS", 60),
+		ScraperBrowserPagesLimit:         getEnvInt("SCRAPER_BROWSER_PAGES_LIMIT", 50),
+		WatcherIntervalInMinutes:         getEnvInt("WATCHER_INTERVAL_IN_MINUTES", 60),
+		WatcherConcurrency:               getEnvInt("WATCHER_CONCURRENCY", 4),
+		WatcherConcurrencyPerMarketplace: getEnvInt("WATCHER_CONCURRENCY_PER_MARKETPLACE", 2),
+		WatcherConcurrencyByMarketplace:  getMarketplaceConcurrency(),
+		WebhookUrl:                       os.Getenv("TELEGRAM_WEBHOOK_URL"),
+		WebhookSecret:                    os.Getenv("TELEGRAM_WEBHOOK_SECRET"),
+		WebhookListenAddress:             getEnvString("TELEGRAM_WEBHOOK_LISTEN", ":8080"),
+		ConversationStore:                getEnvString("CONVERSATION_STORE", app.ConversationStorePostgres),
+	}
+
+	app := app.NewTelegramBotApp(config, logger)
+	app.Run()
+}
+
+// Get concurrency of marketplaces which override the default one (e.g. WATCHER_CONCURRENCY_OZON).
+func getMarketplaceConcurrency() map[marketplace.Marketplace]int {
+	concurrency := make(map[marketplace.Marketplace]int)
+
+	for _, driver := range marketplace.GetDrivers() {
+		if value := getEnvInt(helpers.ConcatStrings("WATCHER_CONCURRENCY_", driver.GetKey()), 0); value > 0 {
+			concurrency[driver.GetMarketplace()] = value
+		}
 	}
 
-	watcherIntervalInMinutes, err := strconv.Atoi(os.Getenv("WATCHER_INTERVAL_IN_MINUTES"))
+	return concurrency
+}
+
+// Get integer environment variable or default value if it's not set or invalid.
+func getEnvInt(name string, defaultValue int) int {
+	value, err := strconv.Atoi(os.Getenv(name))
 	if err != nil {
-		watcherIntervalInMinutes = 60
+		return defaultValue
 	}
 
-	app := app.NewTelegramBotApp(token, logger, scraperTimeoutInSeconds, watcherIntervalInMinutes)
-	app.Run()
+	return value
+}
+
+// Get string environment variable or default value if it's not set.
+func getEnvString(name string, defaultValue string) string {
+	value := os.Getenv(name)
+	if value == "" {
+		return defaultValue
+	}
+
+	return value
 }
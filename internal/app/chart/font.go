@@ -0,0 +1,74 @@
+package chart
+
+import (
+	"image"
+	"image/color"
+)
+
+const (
+	glyphWidth  = 3
+	glyphHeight = 5
+	fontScale   = 2
+)
+
+// Minimal bitmap font, enough to draw prices and dates.
+var glyphs = map[rune][glyphHeight]string{
+	'0': {"###", "#.#", "#.#", "#.#", "###"},
+	'1': {".#.", "##.", ".#.", ".#.", "###"},
+	'2': {"###", "..#", "###", "#..", "###"},
+	'3': {"###", "..#", "###", "..#", "###"},
+	'4': {"#.#", "#.#", "###", "..#", "..#"},
+	'5': {"###", "#..", "###", "..#", "###"},
+	'6': {"###", "#..", "###", "#.#", "###"},
+	'7': {"###", "..#", "..#", "..#", "..#"},
+	'8': {"###", "#.#", "###", "#.#", "###"},
+	'9': {"###", "#.#", "###", "..#", "###"},
+	'.': {"...", "...", "...", "...", ".#."},
+	':': {"...", ".#.", "...", ".#.", "..."},
+	'-': {"...", "...", "###", "...", "..."},
+	' ': {"...", "...", "...", "...", "..."},
+}
+
+// Get width of text in pixels.
+func textWidth(text string) int {
+	length := len([]rune(text))
+	if length == 0 {
+		return 0
+	}
+
+	return (length*(glyphWidth+1) - 1) * fontScale
+}
+
+// Get height of text in pixels.
+func textHeight() int {
+	return glyphHeight * fontScale
+}
+
+// Draw text with its top left corner at given position, unknown characters are skipped.
+func drawText(img *image.RGBA, position image.Point, text string, color color.Color) {
+	x := position.X
+
+	for _, char := range text {
+		glyph, ok := glyphs[char]
+		if !ok {
+			x += (glyphWidth + 1) * fontScale
+			continue
+		}
+
+		for row, line := range glyph {
+			for column, pixel := range line {
+				if pixel != '#' {
+					continue
+				}
+
+				for dy := 0; dy < fontScale; dy++ {
+					for dx := 0; dx < fontScale; dx++ {
+						img.Set(x+column*fontScale+dx, position.Y+row*fontScale+dy, color)
+					}
+				}
+			}
+		}
+
+		x += (glyphWidth + 1) * fontScale
+	}
+}
@@ -0,0 +1,318 @@
+package chart
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"math"
+	"sort"
+	"strconv"
+	"time"
+)
+
+var ErrNoData = errors.New("no data to render")
+
+var (
+	colorBackground = color.RGBA{255, 255, 255, 255}
+	colorAxis       = color.RGBA{140, 140, 140, 255}
+	colorGrid       = color.RGBA{232, 232, 232, 255}
+	colorGap        = color.RGBA{253, 228, 228, 255}
+	colorLine       = color.RGBA{30, 136, 229, 255}
+	colorText       = color.RGBA{70, 70, 70, 255}
+)
+
+const (
+	paddingLeft    = 80
+	paddingRight   = 24
+	paddingTop     = 24
+	paddingBottom  = 40
+	gridLinesCount = 5
+	timeLabelCount = 4
+	lineThickness  = 2
+	pointRadius    = 3
+)
+
+// Single price observation (price is in minor currency).
+type Point struct {
+	Time       time.Time
+	Price      int
+	OutOfStock bool
+}
+
+type PriceChart struct {
+	width  int
+	height int
+}
+
+func NewPriceChart(width int, height int) PriceChart {
+	return PriceChart{
+		width:  width,
+		height: height,
+	}
+}
+
+// Render price-over-time line as PNG image, out of stock periods are shown as gaps.
+func (c *PriceChart) Render(points []Point) ([]byte, error) {
+	points = append([]Point(nil), points...)
+
+	sort.SliceStable(points, func(i int, j int) bool {
+		return points[i].Time.Before(points[j].Time)
+	})
+
+	minPrice, maxPrice, err := c.getPriceRange(points)
+	if err != nil {
+		return nil, err
+	}
+
+	img := image.NewRGBA(image.Rect(0, 0, c.width, c.height))
+	draw.Draw(img, img.Bounds(), &image.Uniform{colorBackground}, image.Point{}, draw.Src)
+
+	area := plotArea{
+		rect:     image.Rect(paddingLeft, paddingTop, c.width-paddingRight, c.height-paddingBottom),
+		from:     points[0].Time,
+		to:       points[len(points)-1].Time,
+		minPrice: minPrice,
+		maxPrice: maxPrice,
+	}
+
+	c.drawGaps(img, area, points)
+	c.drawGrid(img, area)
+	c.drawTimeLabels(img, area)
+	c.drawLine(img, area, points)
+	c.drawAxes(img, area)
+
+	buffer := bytes.NewBuffer(nil)
+	if err := png.Encode(buffer, img); err != nil {
+		return nil, err
+	}
+
+	return buffer.Bytes(), nil
+}
+
+// Get price range (with margins) of in stock points.
+func (c *PriceChart) getPriceRange(points []Point) (int, int, error) {
+	minPrice := math.MaxInt
+	maxPrice := math.MinInt
+
+	for _, point := range points {
+		if point.OutOfStock {
+			continue
+		}
+
+		minPrice = min(minPrice, point.Price)
+		maxPrice = max(maxPrice, point.Price)
+	}
+
+	if minPrice > maxPrice {
+		return 0, 0, ErrNoData
+	}
+
+	margin := (maxPrice - minPrice) / 10
+	if margin == 0 {
+		margin = max(minPrice/10, 100)
+	}
+
+	return max(minPrice-margin, 0), maxPrice + margin, nil
+}
+
+// Shade periods when product was out of stock.
+func (c *PriceChart) drawGaps(img *image.RGBA, area plotArea, points []Point) {
+	for i, point := range points {
+		if !point.OutOfStock {
+			continue
+		}
+
+		fromX := area.x(point.Time)
+		toX := area.rect.Max.X
+
+		if i+1 < len(points) {
+			toX = area.x(points[i+1].Time)
+		}
+
+		gap := image.Rect(fromX, area.rect.Min.Y, max(toX, fromX+lineThickness), area.rect.Max.Y)
+		draw.Draw(img, gap, &image.Uniform{colorGap}, image.Point{}, draw.Src)
+	}
+}
+
+// Draw horizontal grid lines with price labels.
+func (c *PriceChart) drawGrid(img *image.RGBA, area plotArea) {
+	for i := 0; i <= gridLinesCount; i++ {
+		price := area.minPrice + (area.maxPrice-area.minPrice)*i/gridLinesCount
+		y := area.y(price)
+
+		for x := area.rect.Min.X; x < area.rect.Max.X; x++ {
+			img.Set(x, y, colorGrid)
+		}
+
+		label := strconv.Itoa(int(math.Round(float64(price) / 100)))
+		position := image.Point{
+			X: area.rect.Min.X - textWidth(label) - 8,
+			Y: y - textHeight()/2,
+		}
+
+		drawText(img, position, label, colorText)
+	}
+}
+
+// Draw time labels below the plot.
+func (c *PriceChart) drawTimeLabels(img *image.RGBA, area plotArea) {
+	layout := "02.01"
+	if area.to.Sub(area.from) < 48*time.Hour {
+		layout = "15:04"
+	}
+
+	labelsCount := timeLabelCount
+	if !area.to.After(area.from) {
+		labelsCount = 1
+	}
+
+	for i := 0; i < labelsCount; i++ {
+		labelTime := area.from
+
+		if labelsCount > 1 {
+			labelTime = area.from.Add(area.to.Sub(area.from) * time.Duration(i) / time.Duration(labelsCount-1))
+		}
+
+		label := labelTime.Format(layout)
+		x := area.x(labelTime)
+
+		for y := area.rect.Max.Y; y < area.rect.Max.Y+4; y++ {
+			img.Set(x, y, colorAxis)
+		}
+
+		position := image.Point{
+			X: min(max(x-textWidth(label)/2, 0), c.width-textWidth(label)),
+			Y: area.rect.Max.Y + 10,
+		}
+
+		drawText(img, position, label, colorText)
+	}
+}
+
+// Draw price line, which is interrupted by out of stock points.
+func (c *PriceChart) drawLine(img *image.RGBA, area plotArea, points []Point) {
+	var previous *image.Point
+
+	for _, point := range points {
+		if point.OutOfStock {
+			previous = nil
+			continue
+		}
+
+		current := image.Point{
+			X: area.x(point.Time),
+			Y: area.y(point.Price),
+		}
+
+		if previous != nil {
+			c.drawSegment(img, *previous, current)
+		}
+
+		c.drawDot(img, current)
+
+		previous = &current
+	}
+}
+
+// Draw plot axes.
+func (c *PriceChart) drawAxes(img *image.RGBA, area plotArea) {
+	for y := area.rect.Min.Y; y <= area.rect.Max.Y; y++ {
+		img.Set(area.rect.Min.X, y, colorAxis)
+	}
+
+	for x := area.rect.Min.X; x <= area.rect.Max.X; x++ {
+		img.Set(x, area.rect.Max.Y, colorAxis)
+	}
+}
+
+// Draw thick line segment (Bresenham's algorithm).
+func (c *PriceChart) drawSegment(img *image.RGBA, from image.Point, to image.Point) {
+	dx := abs(to.X - from.X)
+	dy := -abs(to.Y - from.Y)
+
+	stepX := 1
+	if from.X > to.X {
+		stepX = -1
+	}
+
+	stepY := 1
+	if from.Y > to.Y {
+		stepY = -1
+	}
+
+	delta := dx + dy
+	x, y := from.X, from.Y
+
+	for {
+		fill := image.Rect(x, y, x+lineThickness, y+lineThickness)
+		draw.Draw(img, fill, &image.Uniform{colorLine}, image.Point{}, draw.Src)
+
+		if x == to.X && y == to.Y {
+			break
+		}
+
+		doubled := 2 * delta
+
+		if doubled >= dy {
+			delta += dy
+			x += stepX
+		}
+
+		if doubled <= dx {
+			delta += dx
+			y += stepY
+		}
+	}
+}
+
+// Draw point marker.
+func (c *PriceChart) drawDot(img *image.RGBA, center image.Point) {
+	for y := -pointRadius; y <= pointRadius; y++ {
+		for x := -pointRadius; x <= pointRadius; x++ {
+			if x*x+y*y > pointRadius*pointRadius {
+				continue
+			}
+
+			img.Set(center.X+x+lineThickness/2, center.Y+y+lineThickness/2, colorLine)
+		}
+	}
+}
+
+// Plot rectangle with its time and price scales.
+type plotArea struct {
+	rect     image.Rectangle
+	from     time.Time
+	to       time.Time
+	minPrice int
+	maxPrice int
+}
+
+// Map time to X coordinate.
+func (a plotArea) x(value time.Time) int {
+	total := a.to.Sub(a.from)
+	if total <= 0 {
+		return a.rect.Min.X + a.rect.Dx()/2
+	}
+
+	ratio := float64(value.Sub(a.from)) / float64(total)
+
+	return a.rect.Min.X + int(math.Round(ratio*float64(a.rect.Dx()-lineThickness)))
+}
+
+// Map price to Y coordinate.
+func (a plotArea) y(price int) int {
+	ratio := float64(price-a.minPrice) / float64(a.maxPrice-a.minPrice)
+
+	return a.rect.Max.Y - int(math.Round(ratio*float64(a.rect.Dy())))
+}
+
+func abs(value int) int {
+	if value < 0 {
+		return -value
+	}
+
+	return value
+}
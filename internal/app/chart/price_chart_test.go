@@ -0,0 +1,64 @@
+package chart_test
+
+import (
+	"bot/internal/app/chart"
+	"bytes"
+	"image/png"
+	"testing"
+	"time"
+)
+
+func TestPriceChartRender(t *testing.T) {
+	now := time.Now()
+
+	points := []chart.Point{
+		{Time: now.Add(-72 * time.Hour), Price: 150000},
+		{Time: now.Add(-48 * time.Hour), Price: 0, OutOfStock: true},
+		{Time: now.Add(-24 * time.Hour), Price: 120000},
+		{Time: now, Price: 99900},
+	}
+
+	priceChart := chart.NewPriceChart(800, 400)
+
+	result, err := priceChart.Render(points)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s.", err)
+	}
+
+	img, err := png.Decode(bytes.NewReader(result))
+	if err != nil {
+		t.Fatalf("Invalid PNG image: %s.", err)
+	}
+
+	if img.Bounds().Dx() != 800 || img.Bounds().Dy() != 400 {
+		t.Errorf("Invalid image size, got: %dx%d, instead of: %dx%d.", img.Bounds().Dx(), img.Bounds().Dy(), 800, 400)
+	}
+}
+
+func TestPriceChartRenderSinglePoint(t *testing.T) {
+	priceChart := chart.NewPriceChart(800, 400)
+
+	_, err := priceChart.Render([]chart.Point{
+		{Time: time.Now(), Price: 150000},
+	})
+
+	if err != nil {
+		t.Errorf("Unexpected error: %s.", err)
+	}
+}
+
+func TestPriceChartRenderNoData(t *testing.T) {
+	priceChart := chart.NewPriceChart(800, 400)
+
+	if _, err := priceChart.Render(nil); err != chart.ErrNoData {
+		t.Errorf("Invalid error, got: %v, instead of: %s.", err, chart.ErrNoData)
+	}
+
+	_, err := priceChart.Render([]chart.Point{
+		{Time: time.Now(), OutOfStock: true},
+	})
+
+	if err != chart.ErrNoData {
+		t.Errorf("Invalid error, got: %v, instead of: %s.", err, chart.ErrNoData)
+	}
+}
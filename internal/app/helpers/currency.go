@@ -1,8 +1,10 @@
 package helpers
 
 import (
+	"errors"
 	"math"
 	"strconv"
+	"strings"
 )
 
 const (
@@ -10,6 +12,8 @@ const (
 	RubleSymbol     string = "₽"
 )
 
+var ErrInvalidAmount = errors.New("invalid currency amount")
+
 // Convert major currency to minor (e.g. 220.50 to 22050).
 func CurrencyToMinor(majorValue float64) int {
 	return int(math.Floor(majorValue * float64(CurrencySubunit)))
@@ -28,3 +32,21 @@ func CurrencyFormat(majorValue float64) string {
 
 	return ConcatStrings(result, "\u00A0", RubleSymbol)
 }
+
+// Parse positive major currency from user input (e.g. "1 299,90 ₽" to 1299.90).
+func CurrencyParse(value string) (float64, error) {
+	value = strings.ToLower(strings.TrimSpace(value))
+
+	for _, suffix := range []string{RubleSymbol, "руб.", "руб", "р.", "р"} {
+		value = strings.TrimSuffix(value, suffix)
+	}
+
+	value = strings.NewReplacer(" ", "", "\u00A0", "", ",", ".").Replace(value)
+
+	majorValue, err := strconv.ParseFloat(value, 64)
+	if err != nil || math.IsNaN(majorValue) || math.IsInf(majorValue, 0) || majorValue <= 0 {
+		return 0, ErrInvalidAmount
+	}
+
+	return math.Round(majorValue*100) / 100, nil
+}
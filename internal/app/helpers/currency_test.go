@@ -34,3 +34,30 @@ func TestCurrencyFormat(t *testing.T) {
 		t.Errorf("Invalid result, got: %s, instead of: %s.", result, target)
 	}
 }
+
+func TestCurrencyParse(t *testing.T) {
+	values := map[string]float64{
+		"220":          220,
+		"220.50":       220.50,
+		"1 299,90 ₽":   1299.90,
+		"1\u00A0500 р": 1500,
+		"99 руб.":      99,
+	}
+
+	for value, target := range values {
+		result, err := helpers.CurrencyParse(value)
+		if err != nil {
+			t.Errorf("Unexpected error for: %s, got: %s.", value, err)
+		}
+
+		if result != target {
+			t.Errorf("Invalid result for: %s, got: %.2f, instead of: %.2f.", value, result, target)
+		}
+	}
+
+	for _, value := range []string{"", "abc", "0", "-15", "10%"} {
+		if _, err := helpers.CurrencyParse(value); err != helpers.ErrInvalidAmount {
+			t.Errorf("Expected error for: %s", value)
+		}
+	}
+}
@@ -0,0 +1,27 @@
+package helpers
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+)
+
+var ErrInvalidPercent = errors.New("invalid percent value")
+
+// Check if user input looks like a percentage (e.g. "15%").
+func IsPercent(value string) bool {
+	return strings.HasSuffix(strings.TrimSpace(value), "%")
+}
+
+// Parse percentage from user input (e.g. "12,5%" to 12.5), value must be within (0; 100).
+func PercentParse(value string) (float64, error) {
+	value = strings.TrimSuffix(strings.TrimSpace(value), "%")
+	value = strings.ReplaceAll(strings.TrimSpace(value), ",", ".")
+
+	percent, err := strconv.ParseFloat(value, 64)
+	if err != nil || percent <= 0 || percent >= 100 {
+		return 0, ErrInvalidPercent
+	}
+
+	return percent, nil
+}
@@ -0,0 +1,41 @@
+package helpers_test
+
+import (
+	"bot/internal/app/helpers"
+	"testing"
+)
+
+func TestIsPercent(t *testing.T) {
+	if !helpers.IsPercent(" 15% ") {
+		t.Errorf("Invalid result, \"15%%\" is a percentage")
+	}
+
+	if helpers.IsPercent("1500") {
+		t.Errorf("Invalid result, \"1500\" is not a percentage")
+	}
+}
+
+func TestPercentParse(t *testing.T) {
+	values := map[string]float64{
+		"15%":    15,
+		"12,5 %": 12.5,
+		"99.9%":  99.9,
+	}
+
+	for value, target := range values {
+		result, err := helpers.PercentParse(value)
+		if err != nil {
+			t.Errorf("Unexpected error for: %s, got: %s.", value, err)
+		}
+
+		if result != target {
+			t.Errorf("Invalid result for: %s, got: %.2f, instead of: %.2f.", value, result, target)
+		}
+	}
+
+	for _, value := range []string{"", "%", "0%", "100%", "-5%", "abc%"} {
+		if _, err := helpers.PercentParse(value); err != helpers.ErrInvalidPercent {
+			t.Errorf("Expected error for: %s", value)
+		}
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"sync"
 	"time"
 )
 
@@ -18,6 +19,7 @@ type FileLogger struct {
 	path         string
 	isSilent     bool
 	timeLocation *time.Location
+	locker       *sync.Mutex
 }
 
 func NewFileLogger(fileName string, isSilent bool) FileLogger {
@@ -37,10 +39,15 @@ func NewFileLogger(fileName string, isSilent bool) FileLogger {
 		path:         filepath.Join(rootDir, "logs", fileName),
 		isSilent:     isSilent,
 		timeLocation: timeLocation,
+		locker:       new(sync.Mutex),
 	}
 }
 
 func (l FileLogger) Println(message ...any) {
+	// output of the standard logger is shared, so calls from multiple goroutines must not interleave
+	l.locker.Lock()
+	defer l.locker.Unlock()
+
 	logFile, err := os.OpenFile(l.path, os.O_APPEND|os.O_RDWR|os.O_CREATE, 0644)
 	if err != nil {
 		log.Panic(err)
@@ -0,0 +1,245 @@
+package marketplace
+
+import (
+	"bot/internal/app/core"
+	"bot/internal/app/helpers"
+	"errors"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+type AlertRuleType string
+
+const (
+	// Price has dropped by at least the given percentage (in hundredths of percent, e.g. 1050 is 10.5%).
+	AlertRuleDropPercent AlertRuleType = "drop_percent"
+
+	// Price has dropped by at least the given amount (in minor currency).
+	AlertRuleDropAmount AlertRuleType = "drop_amount"
+
+	// Price has reached the target price of product.
+	AlertRuleTargetPrice AlertRuleType = "target_price"
+
+	// Price is the lowest one seen in the given number of days.
+	AlertRuleLowestInDays AlertRuleType = "lowest_in_days"
+
+	// Product is back in stock.
+	AlertRuleBackInStock AlertRuleType = "back_in_stock"
+)
+
+const alertRuleMaxDays = 365
+
+var ErrInvalidAlertRule = errors.New("invalid alert rule")
+
+// Condition of product notification.
+type AlertRule struct {
+	core.Model
+	ProductId int
+	Type      AlertRuleType
+	Value     int
+	CreatedAt time.Time
+}
+
+// Rules of product which doesn't have its own ones: notify when target price is reached (if it's set),
+// otherwise on any price drop, and when product is back in stock at any price.
+func DefaultAlertRules(product ProductDto) []AlertRule {
+	if product.GetTargetPrice() > 0 {
+		return []AlertRule{
+			{Type: AlertRuleTargetPrice},
+			{Type: AlertRuleBackInStock},
+		}
+	}
+
+	return []AlertRule{
+		{Type: AlertRuleDropAmount, Value: 1},
+		{Type: AlertRuleBackInStock},
+	}
+}
+
+// Get rules which are fired by scraped data, history has to contain observations before scraping.
+func EvaluateAlertRules(rules []AlertRule, original ProductDto, scraped ProductDto, history []PriceObservation) []AlertRule {
+	var fired []AlertRule
+
+	price := scraped.GetCurrentPrice()
+
+	if scraped.IsOutOfStock() || price <= 0 {
+		return fired
+	}
+
+	for _, rule := range rules {
+		if isAlertRuleFired(rule, original, price, history) {
+			fired = append(fired, rule)
+		}
+	}
+
+	return fired
+}
+
+// Get max number of days of price history which is needed to evaluate rules.
+func GetAlertRulesHistoryDays(rules []AlertRule) int {
+	days := 0
+
+	for _, rule := range rules {
+		if rule.Type == AlertRuleLowestInDays {
+			days = max(days, rule.Value)
+		}
+	}
+
+	return days
+}
+
+// Get price which drops are measured from after scraping. It's the last price user was notified about
+// (or the one at the moment rules were set), it only follows price rise, so slow decline over several scrapes is summed up.
+func GetNextThresholdPrice(original ProductDto, scraped ProductDto, fired []AlertRule) int {
+	price := scraped.GetCurrentPrice()
+
+	if scraped.IsOutOfStock() || price <= 0 {
+		return original.GetThresholdPrice()
+	}
+
+	if len(fired) > 0 || price > original.GetThresholdPrice() {
+		return price
+	}
+
+	return original.GetThresholdPrice()
+}
+
+// Parse rules from user input, rules are separated by commas or new lines:
+// "-10%" (drop by percentage), "-500" (drop by amount), "цель" (target price),
+// "мин 30" (lowest in days), "наличие" (back in stock).
+func ParseAlertRules(input string) ([]AlertRule, error) {
+	var rules []AlertRule
+
+	for _, value := range splitAlertRules(input) {
+		value = strings.ToLower(strings.TrimSpace(value))
+
+		if value == "" {
+			continue
+		}
+
+		rule, err := parseAlertRule(value)
+		if err != nil {
+			return nil, err
+		}
+
+		rules = append(rules, rule)
+	}
+
+	if len(rules) == 0 {
+		return nil, ErrInvalidAlertRule
+	}
+
+	return rules, nil
+}
+
+// Split user input to rules, comma between digits is a decimal separator (e.g. "-12,5%").
+func splitAlertRules(input string) []string {
+	var values []string
+
+	runes := []rune(input)
+	start := 0
+
+	for i, r := range runes {
+		isSeparator := r == ';' || r == '\n' || r == ','
+
+		if r == ',' && i > 0 && i < len(runes)-1 && unicode.IsDigit(runes[i-1]) && unicode.IsDigit(runes[i+1]) {
+			isSeparator = false
+		}
+
+		if isSeparator {
+			values = append(values, string(runes[start:i]))
+			start = i + 1
+		}
+	}
+
+	return append(values, string(runes[start:]))
+}
+
+func parseAlertRule(value string) (AlertRule, error) {
+	switch value {
+	case "цель", "target":
+		return AlertRule{Type: AlertRuleTargetPrice}, nil
+	case "наличие", "stock":
+		return AlertRule{Type: AlertRuleBackInStock}, nil
+	}
+
+	for _, prefix := range []string{"мин", "min"} {
+		if !strings.HasPrefix(value, prefix) {
+			continue
+		}
+
+		days, err := strconv.Atoi(strings.TrimSpace(strings.TrimPrefix(value, prefix)))
+		if err != nil || days < 1 || days > alertRuleMaxDays {
+			return AlertRule{}, ErrInvalidAlertRule
+		}
+
+		return AlertRule{Type: AlertRuleLowestInDays, Value: days}, nil
+	}
+
+	if !strings.HasPrefix(value, "-") {
+		return AlertRule{}, ErrInvalidAlertRule
+	}
+
+	value = strings.TrimPrefix(value, "-")
+
+	if helpers.IsPercent(value) {
+		percent, err := helpers.PercentParse(value)
+		if err != nil {
+			return AlertRule{}, err
+		}
+
+		return AlertRule{Type: AlertRuleDropPercent, Value: int(math.Round(percent * 100))}, nil
+	}
+
+	amount, err := helpers.CurrencyParse(value)
+	if err != nil {
+		return AlertRule{}, err
+	}
+
+	return AlertRule{Type: AlertRuleDropAmount, Value: helpers.CurrencyToMinor(amount)}, nil
+}
+
+func isAlertRuleFired(rule AlertRule, original ProductDto, price int, history []PriceObservation) bool {
+	// price of the last notification (see GetNextThresholdPrice), not just the previous scrape
+	previousPrice := original.GetThresholdPrice()
+
+	switch rule.Type {
+	case AlertRuleDropPercent:
+		return previousPrice > 0 && (previousPrice-price)*100*100 >= rule.Value*previousPrice
+	case AlertRuleDropAmount:
+		return previousPrice > 0 && previousPrice-price >= rule.Value
+	case AlertRuleTargetPrice:
+		targetPrice := original.GetTargetPrice()
+
+		// notify once, when price crosses the target
+		return targetPrice > 0 && price <= targetPrice && (original.IsOutOfStock() || original.GetCurrentPrice() > targetPrice)
+	case AlertRuleLowestInDays:
+		return isLowestPrice(price, history, original.GetScrapedAt().AddDate(0, 0, -rule.Value))
+	case AlertRuleBackInStock:
+		return original.IsOutOfStock()
+	}
+
+	return false
+}
+
+// Check if price is lower than any price observed since the given time (at least one observation is required).
+func isLowestPrice(price int, history []PriceObservation, since time.Time) bool {
+	isObserved := false
+
+	for _, observation := range history {
+		if observation.OutOfStock || observation.Price <= 0 || observation.ScrapedAt.Before(since) {
+			continue
+		}
+
+		if observation.Price <= price {
+			return false
+		}
+
+		isObserved = true
+	}
+
+	return isObserved
+}
@@ -0,0 +1,206 @@
+package marketplace_test
+
+import (
+	"bot/internal/app/marketplace"
+	"testing"
+	"time"
+)
+
+func TestDefaultAlertRules(t *testing.T) {
+	original := &marketplace.Product{ThresholdPrice: 100000, CurrentPrice: 100000}
+
+	cases := map[string]struct {
+		original *marketplace.Product
+		scraped  *marketplace.Product
+		fired    []marketplace.AlertRuleType
+	}{
+		"any drop": {
+			original: original,
+			scraped:  &marketplace.Product{CurrentPrice: 99999},
+			fired:    []marketplace.AlertRuleType{marketplace.AlertRuleDropAmount},
+		},
+		"price went up": {
+			original: original,
+			scraped:  &marketplace.Product{CurrentPrice: 100001},
+		},
+		"out of stock": {
+			original: original,
+			scraped:  &marketplace.Product{OutOfStock: true},
+		},
+		"back in stock": {
+			original: &marketplace.Product{ThresholdPrice: 100000, CurrentPrice: 100000, OutOfStock: true},
+			scraped:  &marketplace.Product{CurrentPrice: 120000},
+			fired:    []marketplace.AlertRuleType{marketplace.AlertRuleBackInStock},
+		},
+		"target not reached": {
+			original: &marketplace.Product{ThresholdPrice: 100000, CurrentPrice: 100000, TargetPrice: 80000},
+			scraped:  &marketplace.Product{CurrentPrice: 90000},
+		},
+		"target reached": {
+			original: &marketplace.Product{ThresholdPrice: 100000, CurrentPrice: 100000, TargetPrice: 80000},
+			scraped:  &marketplace.Product{CurrentPrice: 80000},
+			fired:    []marketplace.AlertRuleType{marketplace.AlertRuleTargetPrice},
+		},
+		"target already reached": {
+			original: &marketplace.Product{ThresholdPrice: 79000, CurrentPrice: 79000, TargetPrice: 80000},
+			scraped:  &marketplace.Product{CurrentPrice: 78000},
+		},
+		"back in stock above target": {
+			original: &marketplace.Product{ThresholdPrice: 100000, CurrentPrice: 100000, TargetPrice: 80000, OutOfStock: true},
+			scraped:  &marketplace.Product{CurrentPrice: 90000},
+			fired:    []marketplace.AlertRuleType{marketplace.AlertRuleBackInStock},
+		},
+		"back in stock below target": {
+			original: &marketplace.Product{ThresholdPrice: 100000, CurrentPrice: 100000, TargetPrice: 80000, OutOfStock: true},
+			scraped:  &marketplace.Product{CurrentPrice: 70000},
+			fired:    []marketplace.AlertRuleType{marketplace.AlertRuleTargetPrice, marketplace.AlertRuleBackInStock},
+		},
+	}
+
+	for name, c := range cases {
+		rules := marketplace.DefaultAlertRules(c.original)
+		fired := marketplace.EvaluateAlertRules(rules, c.original, c.scraped, nil)
+
+		assertFiredRules(t, name, fired, c.fired)
+	}
+}
+
+func TestDropAlertRules(t *testing.T) {
+	original := &marketplace.Product{ThresholdPrice: 200000, CurrentPrice: 200000}
+	rules := []marketplace.AlertRule{
+		{Type: marketplace.AlertRuleDropPercent, Value: 1000},
+		{Type: marketplace.AlertRuleDropAmount, Value: 50000},
+	}
+
+	prices := map[int][]marketplace.AlertRuleType{
+		190000: nil,
+		180001: nil,
+		180000: {marketplace.AlertRuleDropPercent},
+		150000: {marketplace.AlertRuleDropPercent, marketplace.AlertRuleDropAmount},
+	}
+
+	for price, expected := range prices {
+		fired := marketplace.EvaluateAlertRules(rules, original, &marketplace.Product{CurrentPrice: price}, nil)
+
+		assertFiredRules(t, "price drop", fired, expected)
+	}
+}
+
+func TestDropAlertRulesSumUpSlowDecline(t *testing.T) {
+	product := &marketplace.Product{ThresholdPrice: 100000, CurrentPrice: 100000}
+	rules := []marketplace.AlertRule{
+		{Type: marketplace.AlertRuleDropPercent, Value: 1000},
+	}
+
+	steps := []struct {
+		price     int
+		fired     []marketplace.AlertRuleType
+		threshold int
+	}{
+		{price: 96000, threshold: 100000},
+		{price: 93000, threshold: 100000},
+		{price: 90000, fired: []marketplace.AlertRuleType{marketplace.AlertRuleDropPercent}, threshold: 90000},
+		{price: 85000, threshold: 90000},
+		{price: 95000, threshold: 95000},
+	}
+
+	for _, step := range steps {
+		scraped := &marketplace.Product{CurrentPrice: step.price}
+
+		fired := marketplace.EvaluateAlertRules(rules, product, scraped, nil)
+		assertFiredRules(t, "slow decline", fired, step.fired)
+
+		threshold := marketplace.GetNextThresholdPrice(product, scraped, fired)
+		if threshold != step.threshold {
+			t.Errorf("Invalid threshold price after %d, got: %d, instead of: %d.", step.price, threshold, step.threshold)
+		}
+
+		product = &marketplace.Product{ThresholdPrice: threshold, CurrentPrice: step.price}
+	}
+
+	// threshold is kept while product is out of stock
+	threshold := marketplace.GetNextThresholdPrice(product, &marketplace.Product{OutOfStock: true}, nil)
+	if threshold != product.ThresholdPrice {
+		t.Errorf("Invalid threshold price while out of stock, got: %d, instead of: %d.", threshold, product.ThresholdPrice)
+	}
+}
+
+func TestLowestInDaysAlertRule(t *testing.T) {
+	scrapedAt := time.Date(2024, 3, 31, 12, 0, 0, 0, time.UTC)
+	original := &marketplace.Product{ThresholdPrice: 100000, CurrentPrice: 100000, ScrapedAt: scrapedAt}
+	rules := []marketplace.AlertRule{
+		{Type: marketplace.AlertRuleLowestInDays, Value: 7},
+	}
+
+	history := []marketplace.PriceObservation{
+		{Price: 80000, ScrapedAt: scrapedAt.AddDate(0, 0, -10)},
+		{Price: 95000, ScrapedAt: scrapedAt.AddDate(0, 0, -5)},
+		{OutOfStock: true, ScrapedAt: scrapedAt.AddDate(0, 0, -3)},
+		{Price: 100000, ScrapedAt: scrapedAt},
+	}
+
+	prices := map[int][]marketplace.AlertRuleType{
+		96000: nil,
+		95000: nil,
+		94000: {marketplace.AlertRuleLowestInDays},
+	}
+
+	for price, expected := range prices {
+		fired := marketplace.EvaluateAlertRules(rules, original, &marketplace.Product{CurrentPrice: price}, history)
+
+		assertFiredRules(t, "lowest in days", fired, expected)
+	}
+
+	// there is nothing to compare with
+	fired := marketplace.EvaluateAlertRules(rules, original, &marketplace.Product{CurrentPrice: 1}, nil)
+	assertFiredRules(t, "lowest in days without history", fired, nil)
+}
+
+func TestParseAlertRules(t *testing.T) {
+	rules, err := marketplace.ParseAlertRules("-10%, -12,5 %; -500\nцель, мин 30, Наличие")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v.", err)
+	}
+
+	expected := []marketplace.AlertRule{
+		{Type: marketplace.AlertRuleDropPercent, Value: 1000},
+		{Type: marketplace.AlertRuleDropPercent, Value: 1250},
+		{Type: marketplace.AlertRuleDropAmount, Value: 50000},
+		{Type: marketplace.AlertRuleTargetPrice},
+		{Type: marketplace.AlertRuleLowestInDays, Value: 30},
+		{Type: marketplace.AlertRuleBackInStock},
+	}
+
+	if len(rules) != len(expected) {
+		t.Fatalf("Invalid rules count, got: %d, instead of: %d.", len(rules), len(expected))
+	}
+
+	for i, rule := range rules {
+		if rule.Type != expected[i].Type || rule.Value != expected[i].Value {
+			t.Errorf("Invalid rule #%d, got: %s %d, instead of: %s %d.", i+1, rule.Type, rule.Value, expected[i].Type, expected[i].Value)
+		}
+	}
+}
+
+func TestParseAlertRulesErrors(t *testing.T) {
+	for _, input := range []string{"", " , ", "10%", "1500", "-abc", "-150%", "мин", "мин 0", "мин 1000", "скидка"} {
+		if _, err := marketplace.ParseAlertRules(input); err == nil {
+			t.Errorf("Expected error for: \"%s\".", input)
+		}
+	}
+}
+
+func assertFiredRules(t *testing.T, name string, fired []marketplace.AlertRule, expected []marketplace.AlertRuleType) {
+	t.Helper()
+
+	if len(fired) != len(expected) {
+		t.Errorf("Invalid fired rules for: %s, got: %v, instead of: %v.", name, fired, expected)
+		return
+	}
+
+	for i, rule := range fired {
+		if rule.Type != expected[i] {
+			t.Errorf("Invalid fired rule for: %s, got: %s, instead of: %s.", name, rule.Type, expected[i])
+		}
+	}
+}
@@ -0,0 +1,155 @@
+package marketplace
+
+import (
+	"bot/internal/app/helpers"
+	"context"
+	"regexp"
+	"strings"
+
+	"github.com/chromedp/cdproto/cdp"
+	"github.com/chromedp/cdproto/runtime"
+	"github.com/chromedp/chromedp"
+)
+
+const MarketplaceAliExpress Marketplace = 5
+
+const patternAliExpress string = `^(https?://)?(www\.|m\.)?(aliexpress\.ru/item/\d+\.html)(\?.*)?$`
+
+var regexAliExpress = regexp.MustCompile(patternAliExpress)
+var regexAliExpressSku = regexp.MustCompile(`[?&]sku_id=(\d+)`)
+
+type aliExpressDriver struct{}
+
+func init() {
+	RegisterDriver(&aliExpressDriver{})
+}
+
+func (d *aliExpressDriver) GetMarketplace() Marketplace {
+	return MarketplaceAliExpress
+}
+
+func (d *aliExpressDriver) GetName() string {
+	return "AliExpress"
+}
+
+func (d *aliExpressDriver) GetKey() string {
+	return "ALIEXPRESS"
+}
+
+func (d *aliExpressDriver) IsMatchingUrl(url string) bool {
+	return regexAliExpress.MatchString(url)
+}
+
+// Keep only product id and selected SKU variant.
+func (d *aliExpressDriver) GetCleanUrl(url string) string {
+	cleanUrl := regexAliExpress.ReplaceAllString(url, "https://$3")
+
+	if matches := regexAliExpressSku.FindStringSubmatch(url); matches != nil {
+		cleanUrl = helpers.ConcatStrings(cleanUrl, "?sku_id=", matches[1])
+	}
+
+	return cleanUrl
+}
+
+// Scrape product page.
+func (d *aliExpressDriver) Scrape(ctx context.Context, s *Scraper, url string) (ProductDto, error) {
+	product := &ScrapedProduct{
+		url:         url,
+		marketplace: MarketplaceAliExpress,
+	}
+
+	s.logger.Println("Scraping AliExpress URL:", url)
+
+	err := chromedp.Run(
+		ctx,
+		chromedp.Navigate(url),
+
+		chromedp.WaitReady("body", chromedp.ByQuery),
+
+		chromedp.QueryAfter("body", func(ctx context.Context, id runtime.ExecutionContextID, nodes ...*cdp.Node) error {
+			// check if error page (class names are hashed, so only their prefixes are reliable)
+			var hasError bool
+			errorPageJS := `function () {
+				return (this.querySelector('[class*="NotFound_NotFound"]') !== null)
+					|| (this.querySelector('h1') === null);
+			}`
+
+			s.callFunctionOnNode(ctx, nodes[0], errorPageJS, &hasError)
+
+			if hasError {
+				return ErrNotFound
+			}
+
+			var title string
+			titleJS := `function () {
+				return this.querySelector('h1').innerText;
+			}`
+
+			s.callFunctionOnNode(ctx, nodes[0], titleJS, &title)
+
+			product.title = strings.TrimSpace(title)
+			if product.title == "" {
+				return ErrNotFound
+			}
+
+			// add selected SKU variant to the product title (if available)
+			var sku string
+			skuJS := `function () {
+				let nodes = this.querySelectorAll('[class*="SnowSku_SkuPropertyItem__active"]');
+
+				return Array.from(nodes).map(function (node) {
+					let image = node.querySelector('img');
+
+					return node.getAttribute('title') || (image && image.getAttribute('alt')) || node.innerText;
+				}).map(function (value) {
+					return value.trim();
+				}).filter(function (value) {
+					return value !== '';
+				}).join(', ');
+			}`
+
+			s.callFunctionOnNode(ctx, nodes[0], skuJS, &sku)
+
+			sku = strings.TrimSpace(sku)
+			if sku != "" {
+				product.title = helpers.ConcatStrings(product.title, " ", sku)
+			}
+
+			// check if out of stock
+			var isOutOfStock bool
+			outOfStockJS := `function () {
+				return (this.querySelector('[class*="SnowProductAction_SoldOut"], [class*="HazeProductSoldOut"]') !== null);
+			}`
+
+			s.callFunctionOnNode(ctx, nodes[0], outOfStockJS, &isOutOfStock)
+
+			if isOutOfStock {
+				product.outOfStock = true
+
+				return ErrOutOfStock
+			}
+
+			var price string
+			priceJS := `function () {
+				let node = this.querySelector('[class*="SnowPrice_SnowPrice__mainM"], [class*="SnowPrice_SnowPrice__mainS"]');
+
+				return (node !== null) ? node.innerText : '';
+			}`
+
+			s.callFunctionOnNode(ctx, nodes[0], priceJS, &price)
+
+			product.price = helpers.CurrencyToMinor(s.parsePrice(price))
+
+			return nil
+		}, chromedp.ByQuery, chromedp.NodeReady),
+	)
+
+	if s.isUnknownError(err) {
+		s.logger.Println("Unknown error while scraping AliExpress URL:", err)
+		return &ScrapedProduct{}, err
+	}
+
+	s.logger.Println("Done scraping AliExpress URL:", url)
+
+	return product, err
+}
@@ -0,0 +1,23 @@
+package marketplace
+
+import "testing"
+
+func TestAliExpressScrape(t *testing.T) {
+	testScrapeFixtures(t, MarketplaceAliExpress, "aliexpress", []fixtureCase{
+		{
+			file:  "product.html",
+			title: "Беспроводные наушники Baseus Bowie M2s Черный",
+			price: 214937,
+		},
+		{
+			file:       "out_of_stock.html",
+			title:      "Чехол для iPhone 15 Прозрачный",
+			outOfStock: true,
+			err:        ErrOutOfStock,
+		},
+		{
+			file: "not_found.html",
+			err:  ErrNotFound,
+		},
+	})
+}
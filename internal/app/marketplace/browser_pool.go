@@ -0,0 +1,255 @@
+package marketplace
+
+import (
+	"bot/internal/app/helpers"
+	"bot/internal/app/logger"
+	"context"
+	"errors"
+	"math/rand"
+
+	chromedpUndetected "github.com/Davincible/chromedp-undetected"
+	"github.com/chromedp/chromedp"
+)
+
+const browserPagesLimitDefault = 50
+
+type Viewport struct {
+	width  int
+	height int
+}
+
+func (v *Viewport) GetWidth() int {
+	return v.width
+}
+
+func (v *Viewport) GetHeight() int {
+	return v.height
+}
+
+var viewports = []Viewport{
+	{1366, 615},
+	{1440, 765},
+	{1600, 767},
+	{1920, 947},
+}
+
+var viewportChromeVersions = []string{
+	"125.0.6422.76",
+	"128.0.6613.137",
+	"129.0.6668.58",
+	"129.0.6668.89",
+	"130.0.6723.91",
+	"130.0.6723.116",
+	"131.0.6778.108",
+	"131.0.6778.139",
+}
+
+var viewportOsVersions = []string{
+	"Macintosh; Intel Mac OS X 10_10_0",
+	"Macintosh; Intel Mac OS X 10_11_6",
+	"Macintosh; Intel Mac OS X 11_6_6",
+	"Macintosh; Intel Mac OS X 11_7_4",
+	"Macintosh; Intel Mac OS X 12_2_1",
+	"Macintosh; Intel Mac OS X 12_6_4",
+	"Macintosh; Intel Mac OS X 13_0",
+	"Macintosh; Intel Mac OS X 13_2_1",
+
+	"Windows NT 10.0; Win64; x64",
+	"Windows NT 6.1; WOW64",
+	"Windows NT 6.1; Win64; x64",
+
+	"X11; Linux x86_64",
+}
+
+type browserInstance struct {
+	ctx        context.Context
+	cancel     context.CancelFunc
+	pagesCount int
+}
+
+// Check if browser process is gone.
+func (b *browserInstance) isDead() bool {
+	return b.ctx.Err() != nil
+}
+
+// Pool of long-lived browsers, each browser serves one tab at a time.
+type BrowserPool struct {
+	ctx        context.Context
+	logger     logger.LoggerInterface
+	size       int
+	pagesLimit int
+	browsers   chan *browserInstance
+}
+
+// Create new pool of "size" browsers, each browser is restarted after "pagesLimit" opened tabs.
+// Browsers are launched lazily on demand.
+func NewBrowserPool(ctx context.Context, logger logger.LoggerInterface, size int, pagesLimit int) *BrowserPool {
+	if size <= 0 {
+		size = 1
+	}
+
+	if pagesLimit <= 0 {
+		pagesLimit = browserPagesLimitDefault
+	}
+
+	pool := &BrowserPool{
+		ctx:        ctx,
+		logger:     logger,
+		size:       size,
+		pagesLimit: pagesLimit,
+		browsers:   make(chan *browserInstance, size),
+	}
+
+	// empty slots, browsers will be launched on first use
+	for i := 0; i < size; i++ {
+		pool.browsers <- nil
+	}
+
+	return pool
+}
+
+// Open new tab with random user-agent and viewport, blocks until one of browsers is free.
+// Returned release function must be called once the tab is no longer needed.
+func (p *BrowserPool) NewTab(ctx context.Context) (context.Context, func(crashed bool), error) {
+	var browser *browserInstance
+
+	select {
+	case browser = <-p.browsers:
+	case <-ctx.Done():
+		return nil, nil, ctx.Err()
+	case <-p.ctx.Done():
+		return nil, nil, p.ctx.Err()
+	}
+
+	if browser != nil && browser.isDead() {
+		p.logger.Println("Browser has crashed, restarting...")
+		p.shutdown(browser)
+
+		browser = nil
+	}
+
+	if browser == nil {
+		var err error
+
+		browser, err = p.launch()
+		if err != nil {
+			p.browsers <- nil
+			return nil, nil, err
+		}
+	}
+
+	tabContext, cancelTab := chromedp.NewContext(browser.ctx)
+
+	// close tab when caller's context is done
+	stopAfter := context.AfterFunc(ctx, cancelTab)
+
+	userAgent := randomUserAgent()
+	viewport := randomViewport()
+
+	err := chromedp.Run(
+		tabContext,
+		chromedpUndetected.UserAgentOverride(userAgent),
+		chromedp.EmulateViewport(int64(viewport.GetWidth()), int64(viewport.GetHeight())),
+	)
+
+	browser.pagesCount++
+
+	release := func(crashed bool) {
+		stopAfter()
+		cancelTab()
+
+		p.release(browser, crashed)
+	}
+
+	if err != nil {
+		release(true)
+		return nil, nil, err
+	}
+
+	return tabContext, release, nil
+}
+
+// Close all browsers, waits for the tabs in use to be released.
+func (p *BrowserPool) Close() {
+	for i := 0; i < p.size; i++ {
+		browser := <-p.browsers
+
+		if browser != nil {
+			p.shutdown(browser)
+		}
+	}
+
+	p.logger.Println("All browsers are closed")
+}
+
+// Return browser back to the pool, restart it if it's worn out or crashed.
+func (p *BrowserPool) release(browser *browserInstance, crashed bool) {
+	if crashed || browser.isDead() || browser.pagesCount >= p.pagesLimit {
+		p.shutdown(browser)
+
+		browser = nil
+	}
+
+	p.browsers <- browser
+}
+
+// Check if error is caused by browser itself (e.g. lost connection to it), not by scraped page.
+func isBrowserError(err error) bool {
+	browserErrors := []error{
+		chromedp.ErrChannelClosed,
+		chromedp.ErrInvalidContext,
+		chromedp.ErrInvalidTarget,
+		chromedp.ErrInvalidWebsocketMessage,
+	}
+
+	for _, browserError := range browserErrors {
+		if errors.Is(err, browserError) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Launch new browser instance.
+func (p *BrowserPool) launch() (*browserInstance, error) {
+	ctx, cancel, err := chromedpUndetected.New(chromedpUndetected.NewConfig(
+		chromedpUndetected.WithContext(p.ctx),
+		chromedpUndetected.WithHeadless(),
+	))
+
+	if err != nil {
+		p.logger.Println("Unable to initialize browser", err)
+		return nil, err
+	}
+
+	// start browser right away, otherwise the first tab would own it
+	if err := chromedp.Run(ctx); err != nil {
+		p.logger.Println("Unable to start browser", err)
+		cancel()
+		return nil, err
+	}
+
+	return &browserInstance{
+		ctx:    ctx,
+		cancel: cancel,
+	}, nil
+}
+
+// Stop browser process.
+func (p *BrowserPool) shutdown(browser *browserInstance) {
+	browser.cancel()
+}
+
+// Get random viewport.
+func randomViewport() Viewport {
+	return viewports[rand.Intn(len(viewports))]
+}
+
+// Get random user-agent.
+func randomUserAgent() string {
+	os := viewportOsVersions[rand.Intn(len(viewportOsVersions))]
+	chromeVersion := viewportChromeVersions[rand.Intn(len(viewportChromeVersions))]
+
+	return helpers.ConcatStrings("Mozilla/5.0 (", os, ") AppleWebKit/537.36 (KHTML, like Gecko) Chrome/", chromeVersion, " Safari/537.36")
+}
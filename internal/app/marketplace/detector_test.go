@@ -0,0 +1,56 @@
+package marketplace_test
+
+import (
+	"bot/internal/app/marketplace"
+	"testing"
+)
+
+func TestDetectMarketplaceByUrl(t *testing.T) {
+	urls := map[string]marketplace.Marketplace{
+		"https://www.wildberries.ru/catalog/123456/detail.aspx":                                         marketplace.MarketplaceWildberries,
+		"wildberries.ru/catalog/123456/detail.aspx?targetUrl=GP&size=7890":                              marketplace.MarketplaceWildberries,
+		"https://www.ozon.ru/product/some-product-123456/?advert=abc":                                   marketplace.MarketplaceOzon,
+		"https://ozon.ru/t/AbC12dE":                                                                     marketplace.MarketplaceOzon,
+		"https://market.yandex.ru/product--smartfon-apple-iphone-15/1234567890?sku=10345&uniqueId=1":    marketplace.MarketplaceYandexMarket,
+		"market.yandex.ru/card/smartfon-apple-iphone-15/1234567890":                                     marketplace.MarketplaceYandexMarket,
+		"https://market.yandex.ru/product/1234567890/reviews":                                           marketplace.MarketplaceYandexMarket,
+		"https://market.yandex.ru/catalog--smartfony/26893750/list":                                     marketplace.MarketplaceUnknown,
+		"https://megamarket.ru/catalog/details/smartfon-samsung-galaxy-a55-600014561234_12345/":         marketplace.MarketplaceMegamarket,
+		"megamarket.ru/catalog/details/smartfon-samsung-galaxy-a55-600014561234/#?details_block=prices": marketplace.MarketplaceMegamarket,
+		"https://aliexpress.ru/item/1005005570123456.html?sku_id=12000033577123456&spm=a2g2w":           marketplace.MarketplaceAliExpress,
+		"https://m.aliexpress.ru/item/1005005570123456.html":                                            marketplace.MarketplaceAliExpress,
+		"https://aliexpress.ru/store/1234567":                                                           marketplace.MarketplaceUnknown,
+		"https://example.com/catalog/123456/detail.aspx":                                                marketplace.MarketplaceUnknown,
+		"": marketplace.MarketplaceUnknown,
+	}
+
+	for url, target := range urls {
+		result := marketplace.DetectMarketplaceByUrl(url)
+		if result != target {
+			t.Errorf("Invalid result for: %s, got: %d, instead of: %d.", url, result, target)
+		}
+	}
+}
+
+func TestGetCleanUrl(t *testing.T) {
+	urls := map[string]string{
+		"wildberries.ru/catalog/123456/detail.aspx?targetUrl=GP&size=7890":                                          "https://www.wildberries.ru/catalog/123456/detail.aspx?size=7890",
+		"https://www.wildberries.ru/catalog/123456/detail.aspx?targetUrl=GP":                                        "https://www.wildberries.ru/catalog/123456/detail.aspx",
+		"https://www.ozon.ru/product/some-product-123456/?advert=abc":                                               "https://www.ozon.ru/product/some-product-123456/",
+		"https://ozon.ru/t/AbC12dE":                                                                                 "https://www.ozon.ru/t/AbC12dE",
+		"https://market.yandex.ru/product--smartfon-apple-iphone-15/1234567890?uniqueId=1&sku=10345&do-waremd5=abc": "https://market.yandex.ru/product--smartfon-apple-iphone-15/1234567890?sku=10345",
+		"market.yandex.ru/card/smartfon-apple-iphone-15/1234567890/?from=search":                                    "https://market.yandex.ru/card/smartfon-apple-iphone-15/1234567890",
+		"https://megamarket.ru/catalog/details/smartfon-samsung-galaxy-a55-600014561234_12345/?utm_source=x":        "https://megamarket.ru/catalog/details/smartfon-samsung-galaxy-a55-600014561234/",
+		"megamarket.ru/catalog/details/smartfon-samsung-galaxy-a55-600014561234":                                    "https://megamarket.ru/catalog/details/smartfon-samsung-galaxy-a55-600014561234/",
+		"https://m.aliexpress.ru/item/1005005570123456.html?spm=a2g2w&sku_id=12000033577123456":                     "https://aliexpress.ru/item/1005005570123456.html?sku_id=12000033577123456",
+		"aliexpress.ru/item/1005005570123456.html":                                                                  "https://aliexpress.ru/item/1005005570123456.html",
+		"https://www.market.yandex.ru/product/1234567890/reviews":                                                   "https://market.yandex.ru/product/1234567890",
+	}
+
+	for url, target := range urls {
+		result := marketplace.GetCleanUrl(url)
+		if result != target {
+			t.Errorf("Invalid result for: %s, got: %s, instead of: %s.", url, result, target)
+		}
+	}
+}
@@ -0,0 +1,79 @@
+package marketplace
+
+import "context"
+
+// Marketplace driver, to add a new marketplace implement it in a separate file
+// and register with RegisterDriver() in file's init() function.
+type Driver interface {
+	// Marketplace type, which is stored in database, so it must never change.
+	GetMarketplace() Marketplace
+
+	// Marketplace name to display to user.
+	GetName() string
+
+	// Marketplace name in configuration (e.g. "OZON" in WATCHER_CONCURRENCY_OZON).
+	GetKey() string
+
+	// Check if URL is a product page of marketplace.
+	IsMatchingUrl(url string) bool
+
+	// Get canonical product URL without tracking parameters.
+	GetCleanUrl(url string) string
+
+	// Scrape product page in the given browser tab.
+	Scrape(ctx context.Context, s *Scraper, url string) (ProductDto, error)
+}
+
+// Optional driver extension to get product data from marketplace's public JSON API,
+// which is much faster than rendering product page in browser.
+type ApiDriver interface {
+	// Fetch product data, browser is used as a fallback when it fails.
+	ScrapeApi(ctx context.Context, s *Scraper, url string) (ProductDto, error)
+}
+
+// Way the product data was obtained.
+type ScrapeMethod int
+
+const (
+	ScrapeMethodUnknown ScrapeMethod = 0
+	ScrapeMethodBrowser ScrapeMethod = 1
+	ScrapeMethodApi     ScrapeMethod = 2
+)
+
+var drivers []Driver
+
+// Register marketplace driver.
+func RegisterDriver(driver Driver) {
+	if _, exists := GetDriver(driver.GetMarketplace()); exists {
+		panic("marketplace driver is already registered")
+	}
+
+	drivers = append(drivers, driver)
+}
+
+// Get driver of marketplace.
+func GetDriver(marketplace Marketplace) (Driver, bool) {
+	for _, driver := range drivers {
+		if driver.GetMarketplace() == marketplace {
+			return driver, true
+		}
+	}
+
+	return nil, false
+}
+
+// Get all registered drivers.
+func GetDrivers() []Driver {
+	return drivers
+}
+
+// Find driver which is able to handle URL.
+func FindDriverByUrl(url string) (Driver, bool) {
+	for _, driver := range drivers {
+		if driver.IsMatchingUrl(url) {
+			return driver, true
+		}
+	}
+
+	return nil, false
+}
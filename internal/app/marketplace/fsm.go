@@ -8,6 +8,13 @@ const (
 	StateScraping      statemachine.State = "Scraping"
 	StateListing       statemachine.State = "Listing"
 	StateDeleting      statemachine.State = "Deleting"
+
+	StateAskingForTargetPrice  statemachine.State = "AskingForTargetPrice"
+	StateWaitingForTargetPrice statemachine.State = "WaitingForTargetPrice"
+	StateEditingTargetPrice    statemachine.State = "EditingTargetPrice"
+
+	StateEditingAlertRules    statemachine.State = "EditingAlertRules"
+	StateWaitingForAlertRules statemachine.State = "WaitingForAlertRules"
 )
 
 const (
@@ -16,6 +23,13 @@ const (
 	EventScrape     statemachine.Event = "Scrape"
 	EventList       statemachine.Event = "List"
 	EventDelete     statemachine.Event = "Delete"
+
+	EventAskForTargetPrice  statemachine.Event = "AskForTargetPrice"
+	EventWaitForTargetPrice statemachine.Event = "WaitForTargetPrice"
+	EventEditTargetPrice    statemachine.Event = "EditTargetPrice"
+
+	EventEditAlertRules    statemachine.Event = "EditAlertRules"
+	EventWaitForAlertRules statemachine.Event = "WaitForAlertRules"
 )
 
 func NewFsm() statemachine.StateMachine {
@@ -57,6 +71,44 @@ func NewFsm() statemachine.StateMachine {
 			},
 			To: StateDeleting,
 		},
+
+		EventAskForTargetPrice: {
+			From: []statemachine.State{
+				StateScraping,
+			},
+			To: StateAskingForTargetPrice,
+		},
+
+		EventWaitForTargetPrice: {
+			From: []statemachine.State{
+				StateAskingForTargetPrice,
+				StateEditingTargetPrice,
+			},
+			To: StateWaitingForTargetPrice,
+		},
+
+		EventEditTargetPrice: {
+			From: []statemachine.State{
+				statemachine.StateIdle,
+				StateListing,
+			},
+			To: StateEditingTargetPrice,
+		},
+
+		EventEditAlertRules: {
+			From: []statemachine.State{
+				statemachine.StateIdle,
+				StateListing,
+			},
+			To: StateEditingAlertRules,
+		},
+
+		EventWaitForAlertRules: {
+			From: []statemachine.State{
+				StateEditingAlertRules,
+			},
+			To: StateWaitingForAlertRules,
+		},
 	}
 
 	return statemachine.NewFSM(statemachine.StateIdle, transitions)
@@ -0,0 +1,125 @@
+package marketplace
+
+import (
+	"bot/internal/app/helpers"
+	"context"
+	"regexp"
+	"strings"
+
+	"github.com/chromedp/cdproto/cdp"
+	"github.com/chromedp/cdproto/runtime"
+	"github.com/chromedp/chromedp"
+)
+
+const MarketplaceMegamarket Marketplace = 4
+
+const patternMegamarket string = `^(https?://)?(www\.)?(megamarket\.ru/catalog/details/[a-z0-9-]+-\d+)(_\d+)?/?(#.*)?(\?.*)?$`
+
+var regexMegamarket = regexp.MustCompile(patternMegamarket)
+
+type megamarketDriver struct{}
+
+func init() {
+	RegisterDriver(&megamarketDriver{})
+}
+
+func (d *megamarketDriver) GetMarketplace() Marketplace {
+	return MarketplaceMegamarket
+}
+
+func (d *megamarketDriver) GetName() string {
+	return "Мегамаркет"
+}
+
+func (d *megamarketDriver) GetKey() string {
+	return "MEGAMARKET"
+}
+
+func (d *megamarketDriver) IsMatchingUrl(url string) bool {
+	return regexMegamarket.MatchString(url)
+}
+
+// Keep only product slug, merchant suffix and query are dropped.
+func (d *megamarketDriver) GetCleanUrl(url string) string {
+	return regexMegamarket.ReplaceAllString(url, "https://$3/")
+}
+
+// Scrape product page.
+func (d *megamarketDriver) Scrape(ctx context.Context, s *Scraper, url string) (ProductDto, error) {
+	product := &ScrapedProduct{
+		url:         url,
+		marketplace: MarketplaceMegamarket,
+	}
+
+	s.logger.Println("Scraping Megamarket URL:", url)
+
+	err := chromedp.Run(
+		ctx,
+		chromedp.Navigate(url),
+
+		chromedp.WaitReady("body", chromedp.ByQuery),
+
+		chromedp.QueryAfter("body", func(ctx context.Context, id runtime.ExecutionContextID, nodes ...*cdp.Node) error {
+			// check if error page
+			var hasError bool
+			errorPageJS := `function () {
+				return (this.querySelector('.not-found-page') !== null)
+					|| (this.querySelector('h1.pdp-header__title') === null);
+			}`
+
+			s.callFunctionOnNode(ctx, nodes[0], errorPageJS, &hasError)
+
+			if hasError {
+				return ErrNotFound
+			}
+
+			var title string
+			titleJS := `function () {
+				return this.querySelector('h1.pdp-header__title').innerText;
+			}`
+
+			s.callFunctionOnNode(ctx, nodes[0], titleJS, &title)
+
+			product.title = strings.TrimSpace(title)
+			if product.title == "" {
+				return ErrNotFound
+			}
+
+			// check if out of stock
+			var isOutOfStock bool
+			outOfStockJS := `function () {
+				return (this.querySelector('.pdp-sales-block__out-of-stock, .out-of-stock-block') !== null);
+			}`
+
+			s.callFunctionOnNode(ctx, nodes[0], outOfStockJS, &isOutOfStock)
+
+			if isOutOfStock {
+				product.outOfStock = true
+
+				return ErrOutOfStock
+			}
+
+			var price string
+			priceJS := `function () {
+				let node = this.querySelector('.pdp-sales-block__price-final');
+
+				return (node !== null) ? node.innerText : '';
+			}`
+
+			s.callFunctionOnNode(ctx, nodes[0], priceJS, &price)
+
+			product.price = helpers.CurrencyToMinor(s.parsePrice(price))
+
+			return nil
+		}, chromedp.ByQuery, chromedp.NodeReady),
+	)
+
+	if s.isUnknownError(err) {
+		s.logger.Println("Unknown error while scraping Megamarket URL:", err)
+		return &ScrapedProduct{}, err
+	}
+
+	s.logger.Println("Done scraping Megamarket URL:", url)
+
+	return product, err
+}
@@ -0,0 +1,23 @@
+package marketplace
+
+import "testing"
+
+func TestMegamarketScrape(t *testing.T) {
+	testScrapeFixtures(t, MarketplaceMegamarket, "megamarket", []fixtureCase{
+		{
+			file:  "product.html",
+			title: "Смартфон Samsung Galaxy A55 8/256GB синий",
+			price: 3749000,
+		},
+		{
+			file:       "out_of_stock.html",
+			title:      "Пылесос Dyson V15 Detect",
+			outOfStock: true,
+			err:        ErrOutOfStock,
+		},
+		{
+			file: "not_found.html",
+			err:  ErrNotFound,
+		},
+	})
+}
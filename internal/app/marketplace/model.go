@@ -19,6 +19,12 @@ type Product struct {
 	ThresholdPrice int
 	CurrentPrice   int
 	OutOfStock     bool
+	TargetPrice    int
+	IsChatBlocked  bool
+	Active         bool
+
+	// Name of user who has added the product (at the moment of adding).
+	TelegramUserName string
 }
 
 func (p *Product) GetScrapedAt() time.Time {
@@ -37,6 +43,10 @@ func (p *Product) GetTelegramUserId() int {
 	return p.TelegramUserId
 }
 
+func (p *Product) GetTelegramUserName() string {
+	return p.TelegramUserName
+}
+
 func (p *Product) GetUrl() string {
 	return p.Url
 }
@@ -45,6 +55,10 @@ func (p *Product) GetMarketplace() Marketplace {
 	return p.Marketplace
 }
 
+func (p *Product) IsActive() bool {
+	return p.Active
+}
+
 func (p *Product) GetTitle() string {
 	return p.Title
 }
@@ -60,3 +74,43 @@ func (p *Product) GetCurrentPrice() int {
 func (p *Product) IsOutOfStock() bool {
 	return p.OutOfStock
 }
+
+func (p *Product) GetTargetPrice() int {
+	return p.TargetPrice
+}
+
+type PriceObservation struct {
+	core.Model
+	ProductId    int
+	Marketplace  Marketplace
+	Price        int
+	OutOfStock   bool
+	ScrapedAt    time.Time
+	ScrapeMethod ScrapeMethod
+}
+
+type ScrapeJobStatus string
+
+const (
+	ScrapeJobStatusPending ScrapeJobStatus = "pending"
+	ScrapeJobStatusRunning ScrapeJobStatus = "running"
+	ScrapeJobStatusDone    ScrapeJobStatus = "done"
+	ScrapeJobStatusFailed  ScrapeJobStatus = "failed"
+)
+
+// On-demand scrape requested by user, result is delivered by editing the message.
+type ScrapeJob struct {
+	core.Model
+	Url               string
+	TelegramChatId    int
+	TelegramUserId    int
+	TelegramMessageId int
+	Status            ScrapeJobStatus
+	Error             string
+	CreatedAt         time.Time
+	StartedAt         *time.Time
+	FinishedAt        *time.Time
+
+	// Refreshed while job is running, job without heartbeat is lost (e.g. after crash).
+	HeartbeatAt *time.Time
+}
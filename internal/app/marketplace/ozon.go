@@ -0,0 +1,255 @@
+package marketplace
+
+import (
+	"bot/internal/app/helpers"
+	"context"
+	"encoding/json"
+	"fmt"
+	neturl "net/url"
+	"regexp"
+	"strings"
+
+	"github.com/chromedp/cdproto/cdp"
+	"github.com/chromedp/cdproto/runtime"
+	"github.com/chromedp/chromedp"
+)
+
+const MarketplaceOzon Marketplace = 2
+
+const patternOzon string = `^(https?://)?(www.)?(ozon\.ru(/product/[a-z0-9-]+/|/t/[A-Za-z0-9-]+))(\?.+)?$`
+
+var regexOzon = regexp.MustCompile(patternOzon)
+
+// Page composer API, could be replaced in tests.
+var ozonApiUrl = "https://www.ozon.ru/api/entrypoint-api.bx/page/json/v2"
+
+// Page widgets, each state is JSON encoded string.
+type ozonApiResponse struct {
+	WidgetStates map[string]string `json:"widgetStates"`
+}
+
+type ozonApiHeading struct {
+	Title string `json:"title"`
+}
+
+type ozonApiPrice struct {
+	IsAvailable bool   `json:"isAvailable"`
+	Price       string `json:"price"`
+}
+
+type ozonApiOutOfStock struct {
+	SkuName string `json:"skuName"`
+}
+
+type ozonDriver struct{}
+
+func init() {
+	RegisterDriver(&ozonDriver{})
+}
+
+func (d *ozonDriver) GetMarketplace() Marketplace {
+	return MarketplaceOzon
+}
+
+func (d *ozonDriver) GetName() string {
+	return "Ozon"
+}
+
+func (d *ozonDriver) GetKey() string {
+	return "OZON"
+}
+
+func (d *ozonDriver) IsMatchingUrl(url string) bool {
+	return regexOzon.MatchString(url)
+}
+
+// Drop query parameters.
+func (d *ozonDriver) GetCleanUrl(url string) string {
+	return strings.TrimSuffix(regexOzon.ReplaceAllString(url, "https://www.$3"), "?")
+}
+
+// Scrape product page.
+func (d *ozonDriver) Scrape(ctx context.Context, s *Scraper, url string) (ProductDto, error) {
+	product := &ScrapedProduct{
+		url:         url,
+		marketplace: MarketplaceOzon,
+	}
+
+	s.logger.Println("Scraping Ozon URL:", url)
+
+	err := chromedp.Run(
+		ctx,
+		chromedp.Navigate(url),
+
+		chromedp.WaitReady("[data-widget=\"container\"]"),
+
+		chromedp.QueryAfter("[data-widget=\"container\"]", func(ctx context.Context, id runtime.ExecutionContextID, nodes ...*cdp.Node) error {
+			// check if error page
+			var hasError bool
+			errorPageJS := `function () {
+				return (this.querySelector('[data-widget="error"]') !== null);
+			}`
+
+			s.callFunctionOnNode(ctx, nodes[0], errorPageJS, &hasError)
+
+			if hasError {
+				return ErrNotFound
+			}
+
+			// check if out of stock
+			var isOutOfStock bool
+			outOfStockJS := `function () {
+				return (this.querySelector('[data-widget="webOutOfStock"]') !== null);
+			}`
+
+			s.callFunctionOnNode(ctx, nodes[0], outOfStockJS, &isOutOfStock)
+
+			if isOutOfStock {
+				var title string
+				titleJS := `function () {
+					return this.querySelector('p').innerText;
+				}`
+
+				s.callFunctionOnNode(ctx, nodes[0], titleJS, &title)
+
+				title = strings.TrimSpace(title)
+				if title == "" {
+					return ErrNotFound
+				}
+
+				product.title = title
+				product.outOfStock = true
+
+				return ErrOutOfStock
+			}
+
+			// scrape product page
+			var title string
+			titleJS := `function () {
+				return this.querySelector('h1').innerText;
+			}`
+
+			s.callFunctionOnNode(ctx, nodes[0], titleJS, &title)
+
+			product.title = strings.TrimSpace(title)
+
+			// there could be "with ozon card" button with "fake" price
+			var price string
+			priceJS := `function () {
+				let nodes = this.querySelector('[data-widget="webPrice"]').querySelectorAll('span:first-of-type');
+
+				return (nodes.length > 1)
+					? nodes[3].innerText
+					: nodes[0].innerText;
+			}`
+
+			s.callFunctionOnNode(ctx, nodes[0], priceJS, &price)
+
+			product.price = helpers.CurrencyToMinor(s.parsePrice(price))
+
+			return nil
+		}, chromedp.ByQuery, chromedp.NodeVisible),
+	)
+
+	if s.isUnknownError(err) {
+		s.logger.Println("Unknown error while scraping Ozon URL:", err)
+		return &ScrapedProduct{}, err
+	}
+
+	s.logger.Println("Done scraping Ozon URL:", url)
+
+	return product, err
+}
+
+// Get product data from page composer API, price without Ozon card is used as on the product page.
+func (d *ozonDriver) ScrapeApi(ctx context.Context, s *Scraper, url string) (ProductDto, error) {
+	matches := regexOzon.FindStringSubmatch(url)
+	if matches == nil {
+		return &ScrapedProduct{}, ErrUnsupported
+	}
+
+	s.logger.Println("Requesting Ozon API:", url)
+
+	var response ozonApiResponse
+
+	err := s.fetchJson(ctx, helpers.ConcatStrings(ozonApiUrl, "?url=", neturl.QueryEscape(matches[4])), &response)
+	if err != nil {
+		return &ScrapedProduct{}, err
+	}
+
+	product := &ScrapedProduct{
+		url:         url,
+		marketplace: MarketplaceOzon,
+	}
+
+	if _, exists := d.findWidgetState(response, "webError"); exists {
+		return &ScrapedProduct{}, ErrNotFound
+	}
+
+	if state, exists := d.findWidgetState(response, "webOutOfStock"); exists {
+		var outOfStock ozonApiOutOfStock
+		if err := json.Unmarshal([]byte(state), &outOfStock); err != nil {
+			return &ScrapedProduct{}, fmt.Errorf("%w: %s", ErrApiResponse, err)
+		}
+
+		product.title = strings.TrimSpace(outOfStock.SkuName)
+		if product.title == "" {
+			return &ScrapedProduct{}, ErrNotFound
+		}
+
+		product.outOfStock = true
+
+		return product, ErrOutOfStock
+	}
+
+	state, exists := d.findWidgetState(response, "webProductHeading")
+	if !exists {
+		return &ScrapedProduct{}, ErrNotFound
+	}
+
+	var heading ozonApiHeading
+	if err := json.Unmarshal([]byte(state), &heading); err != nil {
+		return &ScrapedProduct{}, fmt.Errorf("%w: %s", ErrApiResponse, err)
+	}
+
+	product.title = strings.TrimSpace(heading.Title)
+	if product.title == "" {
+		return &ScrapedProduct{}, ErrNotFound
+	}
+
+	state, exists = d.findWidgetState(response, "webPrice")
+	if !exists {
+		return &ScrapedProduct{}, ErrApiResponse
+	}
+
+	var price ozonApiPrice
+	if err := json.Unmarshal([]byte(state), &price); err != nil {
+		return &ScrapedProduct{}, fmt.Errorf("%w: %s", ErrApiResponse, err)
+	}
+
+	if !price.IsAvailable {
+		product.outOfStock = true
+
+		return product, ErrOutOfStock
+	}
+
+	product.price = helpers.CurrencyToMinor(s.parsePrice(price.Price))
+	if product.price == 0 {
+		return &ScrapedProduct{}, ErrApiResponse
+	}
+
+	s.logger.Println("Done requesting Ozon API:", url)
+
+	return product, nil
+}
+
+// Find state of widget by its name, keys of states are like "webPrice-3121879-default-1".
+func (d *ozonDriver) findWidgetState(response ozonApiResponse, widget string) (string, bool) {
+	for key, state := range response.WidgetStates {
+		if strings.HasPrefix(key, widget+"-") {
+			return state, true
+		}
+	}
+
+	return "", false
+}
@@ -0,0 +1,70 @@
+package marketplace
+
+import "testing"
+
+func TestOzonScrape(t *testing.T) {
+	testScrapeFixtures(t, MarketplaceOzon, "ozon", []fixtureCase{
+		{
+			file:  "product.html",
+			title: "Термокружка Stanley Classic 0,47 л",
+			price: 249000,
+		},
+		{
+			file:  "with_ozon_card.html",
+			title: "Кофе в зернах Lavazza Qualita Oro 1 кг",
+			price: 129900,
+		},
+		{
+			file:       "out_of_stock.html",
+			title:      "Рюкзак Xiaomi Mi Casual Daypack",
+			outOfStock: true,
+			err:        ErrOutOfStock,
+		},
+		{
+			file: "not_found.html",
+			err:  ErrNotFound,
+		},
+	})
+}
+
+func TestOzonScrapeApi(t *testing.T) {
+	server := newApiTestServer("ozon", "url", map[string]string{
+		"/product/termokruzhka-stanley-123/": "api/product.json",
+		"/product/ryukzak-xiaomi-456/":       "api/out_of_stock.json",
+		"/product/kofe-lavazza-789/":         "api/not_available.json",
+		"/product/deleted-product-000/":      "api/not_found.json",
+	})
+	defer server.Close()
+
+	originalUrl := ozonApiUrl
+	ozonApiUrl = server.URL + "/api/entrypoint-api.bx/page/json/v2"
+	defer func() { ozonApiUrl = originalUrl }()
+
+	testScrapeApiFixtures(t, MarketplaceOzon, []apiFixtureCase{
+		{
+			url:   "https://www.ozon.ru/product/termokruzhka-stanley-123/",
+			title: "Термокружка Stanley Classic 0,47 л",
+			price: 249000,
+		},
+		{
+			url:        "https://www.ozon.ru/product/ryukzak-xiaomi-456/",
+			title:      "Рюкзак Xiaomi Mi Casual Daypack",
+			outOfStock: true,
+			err:        ErrOutOfStock,
+		},
+		{
+			url:        "https://www.ozon.ru/product/kofe-lavazza-789/",
+			title:      "Кофе в зернах Lavazza Qualita Oro 1 кг",
+			outOfStock: true,
+			err:        ErrOutOfStock,
+		},
+		{
+			url: "https://www.ozon.ru/product/deleted-product-000/",
+			err: ErrNotFound,
+		},
+		{
+			url: "https://www.ozon.ru/t/AbC12dE",
+			err: ErrApiResponse,
+		},
+	})
+}
@@ -0,0 +1,97 @@
+package marketplace
+
+import (
+	"bot/internal/app/database"
+	"bot/internal/app/helpers"
+	"bot/internal/app/logger"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+type PostgresAlertRuleRepository struct {
+	db     *database.Postgres
+	logger logger.LoggerInterface
+}
+
+func NewPostgresAlertRuleRepository(db *database.Postgres, logger logger.LoggerInterface) PostgresAlertRuleRepository {
+	return PostgresAlertRuleRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// Find all rules of product.
+func (r *PostgresAlertRuleRepository) FindForProduct(productId int) []AlertRule {
+	sql := "SELECT * FROM product_alert_rules WHERE product_id = @product_id ORDER BY id ASC"
+
+	args := pgx.NamedArgs{
+		"product_id": productId,
+	}
+
+	rows, err := r.db.Connection.Query(r.db.Context, sql, args)
+	if err != nil {
+		r.logger.Println("Unable to execute query:", err)
+		return nil
+	}
+
+	models, err := pgx.CollectRows[AlertRule](rows, r.rowToModel)
+	if err != nil {
+		r.logger.Println("Unable to collect rows:", err)
+		return nil
+	}
+
+	return models
+}
+
+// Replace all rules of product with the given ones.
+func (r *PostgresAlertRuleRepository) ReplaceForProduct(productId int, models []AlertRule) error {
+	transaction, err := r.db.Connection.Begin(r.db.Context)
+	if err != nil {
+		r.logger.Println("Unable to begin transaction:", err)
+		return err
+	}
+
+	defer transaction.Rollback(r.db.Context)
+
+	_, err = transaction.Exec(r.db.Context, "DELETE FROM product_alert_rules WHERE product_id = @product_id", pgx.NamedArgs{
+		"product_id": productId,
+	})
+
+	if err != nil {
+		return err
+	}
+
+	sql := "INSERT INTO product_alert_rules (product_id, type, value, created_at)" +
+		" VALUES (@product_id, @type, @value, @created_at)"
+
+	for _, model := range models {
+		_, err = transaction.Exec(r.db.Context, sql, pgx.NamedArgs{
+			"product_id": productId,
+			"type":       model.Type,
+			"value":      model.Value,
+			"created_at": helpers.TimeToDatabase(time.Now()),
+		})
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return transaction.Commit(r.db.Context)
+}
+
+// Scan data from row to model.
+func (r *PostgresAlertRuleRepository) rowToModel(row pgx.CollectableRow) (AlertRule, error) {
+	model := AlertRule{}
+
+	err := row.Scan(
+		&model.Id,
+		&model.ProductId,
+		&model.Type,
+		&model.Value,
+		&model.CreatedAt,
+	)
+
+	return model, err
+}
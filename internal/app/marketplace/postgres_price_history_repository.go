@@ -0,0 +1,101 @@
+package marketplace
+
+import (
+	"bot/internal/app/database"
+	"bot/internal/app/helpers"
+	"bot/internal/app/logger"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+type PostgresPriceHistoryRepository struct {
+	db     *database.Postgres
+	logger logger.LoggerInterface
+}
+
+func NewPostgresPriceHistoryRepository(db *database.Postgres, logger logger.LoggerInterface) PostgresPriceHistoryRepository {
+	return PostgresPriceHistoryRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// Find all observations of product within time range (oldest first).
+func (r *PostgresPriceHistoryRepository) FindForProductInRange(productId int, from time.Time, to time.Time) []PriceObservation {
+	sql := "SELECT * FROM price_observations" +
+		" WHERE product_id = @product_id AND scraped_at >= @from AND scraped_at <= @to" +
+		" ORDER BY scraped_at ASC"
+
+	args := pgx.NamedArgs{
+		"product_id": productId,
+		"from":       helpers.TimeToDatabase(from),
+		"to":         helpers.TimeToDatabase(to),
+	}
+
+	rows, err := r.db.Connection.Query(r.db.Context, sql, args)
+	if err != nil {
+		r.logger.Println("Unable to execute query:", err)
+		return nil
+	}
+
+	models, err := pgx.CollectRows[PriceObservation](rows, r.rowToModel)
+	if err != nil {
+		r.logger.Println("Unable to collect rows:", err)
+		return nil
+	}
+
+	return models
+}
+
+// Add new observation to database within transaction (e.g. together with product update).
+func (r *PostgresPriceHistoryRepository) Save(transaction pgx.Tx, model PriceObservation) (PriceObservation, error) {
+	sql := `INSERT INTO price_observations (
+		product_id,
+		marketplace,
+		price,
+		out_of_stock,
+		scraped_at,
+		scrape_method
+	) VALUES (
+		@product_id,
+		@marketplace,
+		@price,
+		@out_of_stock,
+		@scraped_at,
+		@scrape_method
+	) RETURNING id`
+
+	args := pgx.NamedArgs{
+		"product_id":    model.ProductId,
+		"marketplace":   model.Marketplace,
+		"price":         model.Price,
+		"out_of_stock":  model.OutOfStock,
+		"scraped_at":    model.ScrapedAt,
+		"scrape_method": model.ScrapeMethod,
+	}
+
+	err := transaction.QueryRow(r.db.Context, sql, args).Scan(&model.Id)
+	if err != nil {
+		return PriceObservation{}, err
+	}
+
+	return model, nil
+}
+
+// Scan data from row to model.
+func (r *PostgresPriceHistoryRepository) rowToModel(row pgx.CollectableRow) (PriceObservation, error) {
+	model := PriceObservation{}
+
+	err := row.Scan(
+		&model.Id,
+		&model.ProductId,
+		&model.Marketplace,
+		&model.Price,
+		&model.OutOfStock,
+		&model.ScrapedAt,
+		&model.ScrapeMethod,
+	)
+
+	return model, err
+}
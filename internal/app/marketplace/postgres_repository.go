@@ -44,7 +44,7 @@ func (r *PostgresRepository) FindOutdatedPaginated(offsetInMinutes int, page int
 	scrapedAtOutdated := currentTime.Add(time.Duration(-offsetInMinutes) * time.Minute)
 
 	sql := "SELECT * FROM products" +
-		" WHERE scraped_at <= @scraped_at_outdated" +
+		" WHERE scraped_at <= @scraped_at_outdated AND is_active AND NOT is_chat_blocked" +
 		" ORDER BY created_at DESC" +
 		" LIMIT @limit" +
 		" OFFSET @offset"
@@ -67,7 +67,7 @@ func (r *PostgresRepository) GetCountOutdated(offsetInMinutes int) int {
 	currentTime := time.Now()
 	scrapedAtOutdated := currentTime.Add(time.Duration(-offsetInMinutes) * time.Minute)
 
-	sql := "SELECT COUNT(*) FROM products WHERE scraped_at <= @scraped_at_outdated"
+	sql := "SELECT COUNT(*) FROM products WHERE scraped_at <= @scraped_at_outdated AND is_active AND NOT is_chat_blocked"
 
 	args := pgx.NamedArgs{
 		"scraped_at_outdated": helpers.TimeToDatabase(scrapedAtOutdated),
@@ -84,7 +84,7 @@ func (r *PostgresRepository) GetCountOutdated(offsetInMinutes int) int {
 // Find all models for user with page navigation.
 func (r *PostgresRepository) FindAllForUserPaginated(telegramChatId int, telegramUserId int, page int, perPage int) []Product {
 	sql := "SELECT * FROM products" +
-		" WHERE telegram_chat_id = @telegram_chat_id AND telegram_user_id = @telegram_user_id" +
+		" WHERE " + r.getOwnerCondition(telegramUserId) +
 		" ORDER BY created_at DESC" +
 		" LIMIT @limit" +
 		" OFFSET @offset"
@@ -105,7 +105,7 @@ func (r *PostgresRepository) FindAllForUserPaginated(telegramChatId int, telegra
 
 // Get count of all models for user.
 func (r *PostgresRepository) GetCountForUser(telegramChatId int, telegramUserId int) int {
-	sql := "SELECT COUNT(*) FROM products WHERE telegram_chat_id = @telegram_chat_id AND telegram_user_id = @telegram_user_id"
+	sql := "SELECT COUNT(*) FROM products WHERE " + r.getOwnerCondition(telegramUserId)
 
 	args := pgx.NamedArgs{
 		"telegram_chat_id": telegramChatId,
@@ -120,9 +120,34 @@ func (r *PostgresRepository) GetCountForUser(telegramChatId int, telegramUserId
 	return count
 }
 
+// Find tracked models of user which price hasn't changed since the given time.
+func (r *PostgresRepository) FindUnchangedForUser(telegramChatId int, telegramUserId int, since time.Time) []Product {
+	sql := "SELECT * FROM products p" +
+		" WHERE " + r.getOwnerCondition(telegramUserId) +
+		" AND p.is_active AND NOT p.is_chat_blocked AND NOT p.out_of_stock AND p.created_at <= @since" +
+		" AND EXISTS (" +
+		" SELECT 1 FROM price_observations o" +
+		" WHERE o.product_id = p.id AND o.scraped_at >= @since" +
+		")" +
+		" AND NOT EXISTS (" +
+		" SELECT 1 FROM price_observations o" +
+		" WHERE o.product_id = p.id AND o.scraped_at >= @since" +
+		" AND (o.out_of_stock OR o.price <> p.current_price)" +
+		")" +
+		" ORDER BY p.created_at DESC"
+
+	args := pgx.NamedArgs{
+		"telegram_chat_id": telegramChatId,
+		"telegram_user_id": telegramUserId,
+		"since":            helpers.TimeToDatabase(since),
+	}
+
+	return r.fetchModels(sql, args)
+}
+
 // Find model for user by URL.
 func (r *PostgresRepository) FindForUserByUrl(telegramChatId int, telegramUserId int, url string) (Product, error) {
-	sql := "SELECT * FROM products WHERE telegram_chat_id = @telegram_chat_id AND telegram_user_id = @telegram_user_id AND url = @url"
+	sql := "SELECT * FROM products WHERE " + r.getOwnerCondition(telegramUserId) + " AND url = @url"
 
 	args := pgx.NamedArgs{
 		"telegram_chat_id": telegramChatId,
@@ -140,7 +165,7 @@ func (r *PostgresRepository) FindForUserByUrl(telegramChatId int, telegramUserId
 
 // Find model for user by slug.
 func (r *PostgresRepository) FindForUserBySlug(telegramChatId int, telegramUserId int, slug string) (Product, error) {
-	sql := "SELECT * FROM products WHERE telegram_chat_id = @telegram_chat_id AND telegram_user_id = @telegram_user_id AND slug = @slug"
+	sql := "SELECT * FROM products WHERE " + r.getOwnerCondition(telegramUserId) + " AND slug = @slug"
 
 	args := pgx.NamedArgs{
 		"telegram_chat_id": telegramChatId,
@@ -169,8 +194,67 @@ func (r *PostgresRepository) Delete(id int) bool {
 	return err == nil
 }
 
+// Activate (or deactivate) model.
+func (r *PostgresRepository) SetActive(id int, isActive bool) error {
+	sql := "UPDATE products SET is_active = @is_active, updated_at = @updated_at WHERE id = @id"
+
+	args := pgx.NamedArgs{
+		"id":         id,
+		"is_active":  isActive,
+		"updated_at": time.Now(),
+	}
+
+	_, err := r.db.Connection.Exec(r.db.Context, sql, args)
+
+	return err
+}
+
+// Activate (or deactivate) all models of user, get count of affected models.
+func (r *PostgresRepository) SetActiveForUser(telegramChatId int, telegramUserId int, isActive bool) (int, error) {
+	sql := "UPDATE products SET is_active = @is_active, updated_at = @updated_at" +
+		" WHERE " + r.getOwnerCondition(telegramUserId) + " AND is_active != @is_active"
+
+	args := pgx.NamedArgs{
+		"telegram_chat_id": telegramChatId,
+		"telegram_user_id": telegramUserId,
+		"is_active":        isActive,
+		"updated_at":       time.Now(),
+	}
+
+	result, err := r.db.Connection.Exec(r.db.Context, sql, args)
+	if err != nil {
+		return 0, err
+	}
+
+	return int(result.RowsAffected()), nil
+}
+
+// Mark all models of chat as blocked (or unblocked), get count of affected models.
+func (r *PostgresRepository) SetChatBlocked(telegramChatId int, isBlocked bool) (int, error) {
+	sql := "UPDATE products SET is_chat_blocked = @is_chat_blocked, updated_at = @updated_at" +
+		" WHERE telegram_chat_id = @telegram_chat_id AND is_chat_blocked != @is_chat_blocked"
+
+	args := pgx.NamedArgs{
+		"telegram_chat_id": telegramChatId,
+		"is_chat_blocked":  isBlocked,
+		"updated_at":       time.Now(),
+	}
+
+	result, err := r.db.Connection.Exec(r.db.Context, sql, args)
+	if err != nil {
+		return 0, err
+	}
+
+	return int(result.RowsAffected()), nil
+}
+
 // Save model data.
 func (r *PostgresRepository) Save(model Product) (Product, error) {
+	return r.SaveWithinTransaction(model, nil)
+}
+
+// Save model data, callback (if it's given) is executed within the same transaction to write related data.
+func (r *PostgresRepository) SaveWithinTransaction(model Product, callback func(transaction pgx.Tx, model Product) error) (Product, error) {
 	transaction, err := r.db.Connection.Begin(r.db.Context)
 	if err != nil {
 		r.logger.Println("Unable to begin transaction:", err)
@@ -180,16 +264,26 @@ func (r *PostgresRepository) Save(model Product) (Product, error) {
 	defer transaction.Rollback(r.db.Context)
 
 	if model.Exists() {
-		model, err = r.updateModel(model)
+		err = r.updateModel(transaction, model)
 	} else {
-		model, err = r.insertModel(model)
+		model.Id, err = r.insertModel(transaction, model)
 	}
 
-	if err == nil {
-		transaction.Commit(r.db.Context)
+	if err != nil {
+		return Product{}, err
 	}
 
-	return model, err
+	if callback != nil {
+		if err := callback(transaction, model); err != nil {
+			return Product{}, err
+		}
+	}
+
+	if err := transaction.Commit(r.db.Context); err != nil {
+		return Product{}, err
+	}
+
+	return r.FindById(model.Id)
 }
 
 // Check if slug is unique.
@@ -210,6 +304,15 @@ func (r *PostgresRepository) IsUniqueSlug(slug string) bool {
 	return !exists
 }
 
+// Get SQL condition of models which belong to user in chat, zero user id means all users of chat (e.g. shared watchlist of group).
+func (r *PostgresRepository) getOwnerCondition(telegramUserId int) string {
+	if telegramUserId == 0 {
+		return "telegram_chat_id = @telegram_chat_id"
+	}
+
+	return "telegram_chat_id = @telegram_chat_id AND telegram_user_id = @telegram_user_id"
+}
+
 // Execute SQL and fetch single model.
 func (r *PostgresRepository) fetchModel(sql string, args pgx.NamedArgs) (Product, error) {
 	model := Product{}
@@ -249,7 +352,7 @@ func (r *PostgresRepository) fetchModels(sql string, args pgx.NamedArgs) []Produ
 }
 
 // Add new item to database.
-func (r *PostgresRepository) insertModel(model Product) (Product, error) {
+func (r *PostgresRepository) insertModel(transaction pgx.Tx, model Product) (int, error) {
 	currentTime := time.Now()
 
 	sql := `INSERT INTO products (
@@ -264,7 +367,9 @@ func (r *PostgresRepository) insertModel(model Product) (Product, error) {
 		title, 
 		threshold_price,
 		current_price,
-		out_of_stock
+		out_of_stock,
+		target_price,
+		telegram_user_name
 	) VALUES (
 		@created_at, 
 		@updated_at, 
@@ -277,49 +382,52 @@ func (r *PostgresRepository) insertModel(model Product) (Product, error) {
 		@title, 
 		@threshold_price,
 		@current_price,
-		@out_of_stock
+		@out_of_stock,
+		@target_price,
+		@telegram_user_name
 	) RETURNING id`
 
 	args := pgx.NamedArgs{
-		"created_at":       currentTime,
-		"updated_at":       currentTime,
-		"scraped_at":       currentTime,
-		"slug":             model.Slug,
-		"telegram_chat_id": model.TelegramChatId,
-		"telegram_user_id": model.TelegramUserId,
-		"url":              model.Url,
-		"marketplace":      model.Marketplace,
-		"title":            model.Title,
-		"threshold_price":  model.ThresholdPrice,
-		"current_price":    model.CurrentPrice,
-		"out_of_stock":     model.OutOfStock,
+		"created_at":         currentTime,
+		"updated_at":         currentTime,
+		"scraped_at":         currentTime,
+		"slug":               model.Slug,
+		"telegram_chat_id":   model.TelegramChatId,
+		"telegram_user_id":   model.TelegramUserId,
+		"url":                model.Url,
+		"marketplace":        model.Marketplace,
+		"title":              model.Title,
+		"threshold_price":    model.ThresholdPrice,
+		"current_price":      model.CurrentPrice,
+		"out_of_stock":       model.OutOfStock,
+		"target_price":       model.TargetPrice,
+		"telegram_user_name": model.TelegramUserName,
 	}
 
-	row := r.db.Connection.QueryRow(r.db.Context, sql, args)
+	row := transaction.QueryRow(r.db.Context, sql, args)
 
 	var id int
 	err := row.Scan(&id)
-	if err != nil {
-		return Product{}, err
-	}
 
-	return r.FindById(id)
+	return id, err
 }
 
 // Update existing item in database.
-func (r *PostgresRepository) updateModel(model Product) (Product, error) {
+func (r *PostgresRepository) updateModel(transaction pgx.Tx, model Product) error {
 	sql := `UPDATE products SET (
 		updated_at,
 		scraped_at,
 		threshold_price,
 		current_price,
-		out_of_stock
+		out_of_stock,
+		target_price
 	)=(
 		@updated_at,
 		@scraped_at,
 		@threshold_price,
 		@current_price,
-		@out_of_stock
+		@out_of_stock,
+		@target_price
 	) WHERE id=@id`
 
 	args := pgx.NamedArgs{
@@ -329,14 +437,12 @@ func (r *PostgresRepository) updateModel(model Product) (Product, error) {
 		"threshold_price": model.GetThresholdPrice(),
 		"current_price":   model.GetCurrentPrice(),
 		"out_of_stock":    model.IsOutOfStock(),
+		"target_price":    model.GetTargetPrice(),
 	}
 
-	_, err := r.db.Connection.Exec(r.db.Context, sql, args)
-	if err != nil {
-		return Product{}, err
-	}
+	_, err := transaction.Exec(r.db.Context, sql, args)
 
-	return r.FindById(model.Id)
+	return err
 }
 
 // Scan data from row to model.
@@ -357,6 +463,10 @@ func (r *PostgresRepository) rowToModel(row pgx.CollectableRow) (Product, error)
 		&model.ThresholdPrice,
 		&model.CurrentPrice,
 		&model.OutOfStock,
+		&model.TargetPrice,
+		&model.IsChatBlocked,
+		&model.Active,
+		&model.TelegramUserName,
 	)
 
 	return model, err
@@ -0,0 +1,159 @@
+package marketplace
+
+import (
+	"bot/internal/app/database"
+	"bot/internal/app/helpers"
+	"bot/internal/app/logger"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+type PostgresScrapeJobRepository struct {
+	db     *database.Postgres
+	logger logger.LoggerInterface
+}
+
+func NewPostgresScrapeJobRepository(db *database.Postgres, logger logger.LoggerInterface) PostgresScrapeJobRepository {
+	return PostgresScrapeJobRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// Add new pending job.
+func (r *PostgresScrapeJobRepository) Create(model ScrapeJob) (ScrapeJob, error) {
+	sql := `INSERT INTO scrape_jobs (
+		url,
+		telegram_chat_id,
+		telegram_user_id,
+		telegram_message_id,
+		status,
+		created_at
+	) VALUES (
+		@url,
+		@telegram_chat_id,
+		@telegram_user_id,
+		@telegram_message_id,
+		@status,
+		@created_at
+	) RETURNING id`
+
+	model.Status = ScrapeJobStatusPending
+	model.CreatedAt = time.Now()
+
+	args := pgx.NamedArgs{
+		"url":                 model.Url,
+		"telegram_chat_id":    model.TelegramChatId,
+		"telegram_user_id":    model.TelegramUserId,
+		"telegram_message_id": model.TelegramMessageId,
+		"status":              model.Status,
+		"created_at":          helpers.TimeToDatabase(model.CreatedAt),
+	}
+
+	err := r.db.Connection.QueryRow(r.db.Context, sql, args).Scan(&model.Id)
+	if err != nil {
+		return ScrapeJob{}, err
+	}
+
+	return model, nil
+}
+
+// Take the oldest pending job and mark it as running, jobs taken by other instances are skipped.
+func (r *PostgresScrapeJobRepository) ClaimNext() (ScrapeJob, error) {
+	sql := "UPDATE scrape_jobs SET status = @running, started_at = @started_at, heartbeat_at = @started_at" +
+		" WHERE id = (" +
+		" SELECT id FROM scrape_jobs WHERE status = @pending ORDER BY id ASC LIMIT 1 FOR UPDATE SKIP LOCKED" +
+		" ) RETURNING *"
+
+	args := pgx.NamedArgs{
+		"running":    ScrapeJobStatusRunning,
+		"pending":    ScrapeJobStatusPending,
+		"started_at": helpers.TimeToDatabase(time.Now()),
+	}
+
+	rows, err := r.db.Connection.Query(r.db.Context, sql, args)
+	if err != nil {
+		return ScrapeJob{}, err
+	}
+
+	model, err := pgx.CollectOneRow(rows, r.rowToModel)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return ScrapeJob{}, nil
+	}
+
+	return model, err
+}
+
+// Mark running job as finished with the given status, false is returned if job is not running (e.g. it's already finished).
+func (r *PostgresScrapeJobRepository) Finish(id int, status ScrapeJobStatus, errorMessage string) (bool, error) {
+	sql := "UPDATE scrape_jobs SET status = @status, error = @error, finished_at = @finished_at WHERE id = @id AND status = @running"
+
+	args := pgx.NamedArgs{
+		"id":          id,
+		"status":      status,
+		"running":     ScrapeJobStatusRunning,
+		"error":       errorMessage,
+		"finished_at": helpers.TimeToDatabase(time.Now()),
+	}
+
+	result, err := r.db.Connection.Exec(r.db.Context, sql, args)
+	if err != nil {
+		return false, err
+	}
+
+	return result.RowsAffected() > 0, nil
+}
+
+// Refresh heartbeat of running jobs.
+func (r *PostgresScrapeJobRepository) Heartbeat(ids []int) error {
+	sql := "UPDATE scrape_jobs SET heartbeat_at = @heartbeat_at WHERE id = ANY(@ids) AND status = @running"
+
+	args := pgx.NamedArgs{
+		"ids":          ids,
+		"running":      ScrapeJobStatusRunning,
+		"heartbeat_at": helpers.TimeToDatabase(time.Now()),
+	}
+
+	_, err := r.db.Connection.Exec(r.db.Context, sql, args)
+
+	return err
+}
+
+// Return running jobs which heartbeat has stopped before the given time back to queue (e.g. after crash).
+func (r *PostgresScrapeJobRepository) RequeueStale(heartbeatBefore time.Time) error {
+	sql := "UPDATE scrape_jobs SET status = @pending, started_at = NULL, heartbeat_at = NULL" +
+		" WHERE status = @running AND heartbeat_at < @heartbeat_before"
+
+	args := pgx.NamedArgs{
+		"pending":          ScrapeJobStatusPending,
+		"running":          ScrapeJobStatusRunning,
+		"heartbeat_before": helpers.TimeToDatabase(heartbeatBefore),
+	}
+
+	_, err := r.db.Connection.Exec(r.db.Context, sql, args)
+
+	return err
+}
+
+// Scan data from row to model.
+func (r *PostgresScrapeJobRepository) rowToModel(row pgx.CollectableRow) (ScrapeJob, error) {
+	model := ScrapeJob{}
+
+	err := row.Scan(
+		&model.Id,
+		&model.Url,
+		&model.TelegramChatId,
+		&model.TelegramUserId,
+		&model.TelegramMessageId,
+		&model.Status,
+		&model.Error,
+		&model.CreatedAt,
+		&model.StartedAt,
+		&model.FinishedAt,
+		&model.HeartbeatAt,
+	)
+
+	return model, err
+}
@@ -0,0 +1,60 @@
+package marketplace
+
+import (
+	"bot/internal/app/database"
+	"bot/internal/app/helpers"
+	"bot/internal/app/logger"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+type PostgresSharedWatchlistRepository struct {
+	db     *database.Postgres
+	logger logger.LoggerInterface
+}
+
+func NewPostgresSharedWatchlistRepository(db *database.Postgres, logger logger.LoggerInterface) PostgresSharedWatchlistRepository {
+	return PostgresSharedWatchlistRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// Check if products of chat are shared by all its members.
+func (r *PostgresSharedWatchlistRepository) IsShared(telegramChatId int) bool {
+	sql := "SELECT EXISTS (SELECT * FROM shared_watchlists WHERE telegram_chat_id = @telegram_chat_id)"
+
+	args := pgx.NamedArgs{
+		"telegram_chat_id": telegramChatId,
+	}
+
+	var exists bool
+
+	err := r.db.Connection.QueryRow(r.db.Context, sql, args).Scan(&exists)
+	if err != nil {
+		r.logger.Println("Unable to check shared watchlist:", err)
+		return false
+	}
+
+	return exists
+}
+
+// Share (or unshare) products of chat.
+func (r *PostgresSharedWatchlistRepository) SetShared(telegramChatId int, isShared bool) error {
+	sql := "DELETE FROM shared_watchlists WHERE telegram_chat_id = @telegram_chat_id"
+
+	if isShared {
+		sql = "INSERT INTO shared_watchlists (telegram_chat_id, created_at) VALUES (@telegram_chat_id, @created_at)" +
+			" ON CONFLICT (telegram_chat_id) DO NOTHING"
+	}
+
+	args := pgx.NamedArgs{
+		"telegram_chat_id": telegramChatId,
+		"created_at":       helpers.TimeToDatabase(time.Now()),
+	}
+
+	_, err := r.db.Connection.Exec(r.db.Context, sql, args)
+
+	return err
+}
@@ -0,0 +1,170 @@
+package marketplace
+
+import (
+	"bot/internal/app/logger"
+	"context"
+	"sync"
+	"time"
+)
+
+type ScrapeJobRepository interface {
+	Create(model ScrapeJob) (ScrapeJob, error)
+	ClaimNext() (ScrapeJob, error)
+	Finish(id int, status ScrapeJobStatus, errorMessage string) (bool, error)
+	Heartbeat(ids []int) error
+	RequeueStale(heartbeatBefore time.Time) error
+}
+
+const (
+	scrapeJobPollInterval      = 5 * time.Second
+	scrapeJobHeartbeatInterval = time.Minute
+
+	// Running job without heartbeat for this time is lost, so it's returned to the queue.
+	scrapeJobStaleTimeout = 3 * time.Minute
+)
+
+// Queue of on-demand scrapes, which are executed by worker pool with priority over background checks.
+type ScrapeJobQueue struct {
+	repository ScrapeJobRepository
+	pool       *WorkerPool
+	logger     logger.LoggerInterface
+	notify     chan struct{}
+
+	// Ids of jobs which are submitted to worker pool of this process.
+	running sync.Map
+}
+
+func NewScrapeJobQueue(repository ScrapeJobRepository, pool *WorkerPool, logger logger.LoggerInterface) *ScrapeJobQueue {
+	return &ScrapeJobQueue{
+		repository: repository,
+		pool:       pool,
+		logger:     logger,
+		notify:     make(chan struct{}, 1),
+	}
+}
+
+// Add job to the queue.
+func (q *ScrapeJobQueue) Enqueue(job ScrapeJob) (ScrapeJob, error) {
+	job, err := q.repository.Create(job)
+	if err != nil {
+		return ScrapeJob{}, err
+	}
+
+	// wake up the queue without waiting for the next poll
+	select {
+	case q.notify <- struct{}{}:
+	default:
+	}
+
+	return job, nil
+}
+
+// Take jobs from the queue and pass them to worker pool until context is cancelled,
+// handler is executed by the worker when scraping is done.
+func (q *ScrapeJobQueue) Run(ctx context.Context, handler func(job ScrapeJob, scraped ProductDto, err error)) {
+	go q.keepAlive(ctx)
+
+	for {
+		job, err := q.repository.ClaimNext()
+		if err != nil {
+			q.logger.Println("Unable to claim scrape job:", err)
+		}
+
+		if job.Exists() {
+			q.submit(ctx, job, handler)
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-q.notify:
+		case <-time.After(scrapeJobPollInterval):
+		}
+	}
+}
+
+// Refresh heartbeat of jobs running in this process and requeue the lost ones until context is cancelled.
+func (q *ScrapeJobQueue) keepAlive(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(scrapeJobHeartbeatInterval):
+		}
+
+		var ids []int
+
+		q.running.Range(func(id any, _ any) bool {
+			ids = append(ids, id.(int))
+			return true
+		})
+
+		if len(ids) > 0 {
+			if err := q.repository.Heartbeat(ids); err != nil {
+				q.logger.Println("Unable to refresh heartbeat of scrape jobs:", err)
+			}
+		}
+
+		if err := q.repository.RequeueStale(time.Now().Add(-scrapeJobStaleTimeout)); err != nil {
+			q.logger.Println("Unable to requeue stale scrape jobs:", err)
+		}
+	}
+}
+
+// Submit job to worker pool as a priority task.
+func (q *ScrapeJobQueue) submit(ctx context.Context, job ScrapeJob, handler func(job ScrapeJob, scraped ProductDto, err error)) {
+	task := ScrapeTask{
+		Url:      job.Url,
+		Priority: true,
+		Callback: func(scraped ProductDto, err error) {
+			q.complete(job, scraped, err, handler)
+		},
+	}
+
+	q.running.Store(job.Id, struct{}{})
+
+	if err := q.pool.Submit(ctx, task); err != nil {
+		q.logger.Println("Unable to submit scrape job", job.Id, ":", err)
+
+		// job will be returned to the queue as stale, if it's cancelled by shutdown
+		if ctx.Err() == nil {
+			q.complete(job, &ScrapedProduct{}, err, handler)
+		} else {
+			q.running.Delete(job.Id)
+		}
+	}
+}
+
+// Finish job and pass result to handler, result of already finished job is ignored, so user sees only the first one.
+func (q *ScrapeJobQueue) complete(job ScrapeJob, scraped ProductDto, err error, handler func(job ScrapeJob, scraped ProductDto, err error)) {
+	q.running.Delete(job.Id)
+
+	if q.finish(job, err) {
+		handler(job, scraped, err)
+	}
+}
+
+// Mark job as finished, known scraping errors (e.g. product not found) are valid results.
+// False is returned if job has been already finished.
+func (q *ScrapeJobQueue) finish(job ScrapeJob, err error) bool {
+	status := ScrapeJobStatusDone
+	errorMessage := ""
+
+	if err != nil {
+		errorMessage = err.Error()
+	}
+
+	if err != nil && err != ErrOutOfStock && err != ErrNotFound {
+		status = ScrapeJobStatusFailed
+	}
+
+	isFinished, err := q.repository.Finish(job.Id, status, errorMessage)
+	if err != nil {
+		// job is left without heartbeat, so it will be returned to the queue and scraped again
+		q.logger.Println("Unable to finish scrape job", job.Id, ":", err)
+		return false
+	}
+
+	return isFinished
+}
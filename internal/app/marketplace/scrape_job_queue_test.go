@@ -0,0 +1,64 @@
+package marketplace
+
+import (
+	"bot/internal/app/core"
+	"errors"
+	"testing"
+	"time"
+)
+
+type fakeScrapeJobRepository struct {
+	statuses map[int]ScrapeJobStatus
+}
+
+func (r *fakeScrapeJobRepository) Create(model ScrapeJob) (ScrapeJob, error) {
+	return model, nil
+}
+
+func (r *fakeScrapeJobRepository) ClaimNext() (ScrapeJob, error) {
+	return ScrapeJob{}, nil
+}
+
+func (r *fakeScrapeJobRepository) Finish(id int, status ScrapeJobStatus, errorMessage string) (bool, error) {
+	if r.statuses[id] != ScrapeJobStatusRunning {
+		return false, nil
+	}
+
+	r.statuses[id] = status
+
+	return true, nil
+}
+
+func (r *fakeScrapeJobRepository) Heartbeat(ids []int) error {
+	return nil
+}
+
+func (r *fakeScrapeJobRepository) RequeueStale(heartbeatBefore time.Time) error {
+	return nil
+}
+
+func TestScrapeJobQueueCompleteOnce(t *testing.T) {
+	repository := &fakeScrapeJobRepository{
+		statuses: map[int]ScrapeJobStatus{1: ScrapeJobStatusRunning},
+	}
+
+	queue := NewScrapeJobQueue(repository, nil, testLogger{t: t})
+	job := ScrapeJob{Model: core.Model{Id: 1}}
+
+	handled := 0
+	handler := func(job ScrapeJob, scraped ProductDto, err error) {
+		handled++
+	}
+
+	// e.g. the same job is scraped again after it has been requeued
+	queue.complete(job, &ScrapedProduct{}, nil, handler)
+	queue.complete(job, &ScrapedProduct{}, errors.New("context canceled"), handler)
+
+	if handled != 1 {
+		t.Errorf("Invalid handled results count, got: %d, instead of: %d.", handled, 1)
+	}
+
+	if repository.statuses[1] != ScrapeJobStatusDone {
+		t.Errorf("Invalid job status, got: %s, instead of: %s.", repository.statuses[1], ScrapeJobStatusDone)
+	}
+}
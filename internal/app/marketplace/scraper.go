@@ -1,18 +1,18 @@
 package marketplace
 
 import (
-	"bot/internal/app/helpers"
 	"bot/internal/app/logger"
 	"context"
+	"encoding/json"
 	"errors"
+	"fmt"
 	"math"
-	"math/rand"
+	"net/http"
 	"regexp"
 	"strconv"
 	"strings"
 	"time"
 
-	chromedpUndetected "github.com/Davincible/chromedp-undetected"
 	"github.com/chromedp/cdproto/cdp"
 	"github.com/chromedp/cdproto/dom"
 	"github.com/chromedp/cdproto/runtime"
@@ -26,6 +26,7 @@ type ScrapedProduct struct {
 	title       string
 	price       int
 	outOfStock  bool
+	method      ScrapeMethod
 }
 
 func (p *ScrapedProduct) GetScrapedAt() time.Time {
@@ -44,6 +45,10 @@ func (p *ScrapedProduct) GetTelegramUserId() int {
 	return 0
 }
 
+func (p *ScrapedProduct) GetTelegramUserName() string {
+	return ""
+}
+
 func (p *ScrapedProduct) GetUrl() string {
 	return p.url
 }
@@ -68,132 +73,119 @@ func (p *ScrapedProduct) IsOutOfStock() bool {
 	return p.outOfStock
 }
 
-type Viewport struct {
-	width  int
-	height int
-}
-
-func (v *Viewport) GetWidth() int {
-	return v.width
-}
-
-func (v *Viewport) GetHeight() int {
-	return v.height
+func (p *ScrapedProduct) GetTargetPrice() int {
+	return 0
 }
 
-var viewports = []Viewport{
-	{1366, 615},
-	{1440, 765},
-	{1600, 767},
-	{1920, 947},
+func (p *ScrapedProduct) GetScrapeMethod() ScrapeMethod {
+	return p.method
 }
 
-var viewportChromeVersions = []string{
-	"125.0.6422.76",
-	"128.0.6613.137",
-	"129.0.6668.58",
-	"129.0.6668.89",
-	"130.0.6723.91",
-	"130.0.6723.116",
-	"131.0.6778.108",
-	"131.0.6778.139",
-}
+// Get method which was used to scrape product, it's unknown for products which were not scraped.
+func GetScrapeMethod(product ProductDto) ScrapeMethod {
+	scraped, ok := product.(*ScrapedProduct)
+	if !ok {
+		return ScrapeMethodUnknown
+	}
 
-var viewportOsVersions = []string{
-	"Macintosh; Intel Mac OS X 10_10_0",
-	"Macintosh; Intel Mac OS X 10_11_6",
-	"Macintosh; Intel Mac OS X 11_6_6",
-	"Macintosh; Intel Mac OS X 11_7_4",
-	"Macintosh; Intel Mac OS X 12_2_1",
-	"Macintosh; Intel Mac OS X 12_6_4",
-	"Macintosh; Intel Mac OS X 13_0",
-	"Macintosh; Intel Mac OS X 13_2_1",
-
-	"Windows NT 10.0; Win64; x64",
-	"Windows NT 6.1; WOW64",
-	"Windows NT 6.1; Win64; x64",
-
-	"X11; Linux x86_64",
+	return scraped.method
 }
 
 var ErrEmptyUrl = errors.New("empty marketplace url")
 var ErrUnsupported = errors.New("unsupported marketplace")
 var ErrOutOfStock error = errors.New("product out of stock")
 var ErrNotFound error = errors.New("product not found")
+var ErrApiResponse error = errors.New("unexpected api response")
+
+const apiTimeout = 10 * time.Second
 
 type Scraper struct {
-	ctx              context.Context
+	pool             *BrowserPool
 	logger           logger.LoggerInterface
 	timeoutInSeconds int
+	httpClient       *http.Client
 }
 
-func NewScraper(logger logger.LoggerInterface, timeoutInSeconds int) Scraper {
+func NewScraper(pool *BrowserPool, logger logger.LoggerInterface, timeoutInSeconds int) Scraper {
 	if timeoutInSeconds <= 0 {
 		timeoutInSeconds = 60
 	}
 
 	return Scraper{
+		pool:             pool,
 		logger:           logger,
 		timeoutInSeconds: timeoutInSeconds,
+		httpClient: &http.Client{
+			Timeout: apiTimeout,
+		},
 	}
 }
 
-// Create new browser instance.
-func (s *Scraper) newBrowserInstance() (context.Context, context.CancelFunc, error) {
-	var instance context.Context
-	var cancel context.CancelFunc
-	var err error
+// Scrape target URL.
+func (s *Scraper) Scrape(url string) (ProductDto, error) {
+	return s.ScrapeWithContext(context.Background(), url)
+}
 
-	instance, cancel, err = chromedpUndetected.New(chromedpUndetected.NewConfig(
-		chromedpUndetected.WithHeadless(),
-		chromedpUndetected.WithTimeout(time.Duration(s.timeoutInSeconds)*time.Second),
-	))
+// Scrape target URL via marketplace API (if supported by driver) or in a browser tab from the pool,
+// tab is closed when context is cancelled.
+func (s *Scraper) ScrapeWithContext(ctx context.Context, url string) (ProductDto, error) {
+	if url == "" {
+		return &ScrapedProduct{}, ErrEmptyUrl
+	}
 
-	if err != nil {
-		return nil, nil, err
+	driver, ok := FindDriverByUrl(url)
+	if !ok {
+		return &ScrapedProduct{}, ErrUnsupported
 	}
 
-	return instance, cancel, nil
-}
+	if apiDriver, ok := driver.(ApiDriver); ok {
+		scraped, err := s.scrapeWithApi(ctx, apiDriver, url)
+		if err == nil || err == ErrOutOfStock {
+			return scraped, err
+		}
 
-// Get random viewport.
-func (s *Scraper) randomViewport() Viewport {
-	return viewports[rand.Intn(len(viewports))]
-}
+		if ctx.Err() != nil {
+			return &ScrapedProduct{}, ctx.Err()
+		}
 
-// Get random user-agent.
-func (s *Scraper) randomUserAgent() string {
-	os := viewportOsVersions[rand.Intn(len(viewportOsVersions))]
-	chromeVersion := viewportChromeVersions[rand.Intn(len(viewportChromeVersions))]
+		s.logger.Println("Unable to scrape via API, falling back to browser:", url, err)
+	}
 
-	return helpers.ConcatStrings("Mozilla/5.0 (", os, ") AppleWebKit/537.36 (KHTML, like Gecko) Chrome/", chromeVersion, " Safari/537.36")
+	return s.scrapeWithDriver(ctx, driver, url)
 }
 
-// Scrape target URL.
-func (s *Scraper) Scrape(url string) (ProductDto, error) {
-	if url == "" {
-		return &ScrapedProduct{}, ErrEmptyUrl
+// Get product data from marketplace API.
+func (s *Scraper) scrapeWithApi(ctx context.Context, driver ApiDriver, url string) (ProductDto, error) {
+	scraped, err := driver.ScrapeApi(ctx, s, url)
+
+	if product, ok := scraped.(*ScrapedProduct); ok {
+		product.method = ScrapeMethodApi
 	}
 
-	var cancel context.CancelFunc
-	var err error
+	return scraped, err
+}
 
-	s.ctx, cancel, err = s.newBrowserInstance()
+// Scrape URL with the given marketplace driver.
+func (s *Scraper) scrapeWithDriver(ctx context.Context, driver Driver, url string) (ProductDto, error) {
+	tabContext, release, err := s.pool.NewTab(ctx)
 	if err != nil {
-		s.logger.Println("Unable to initialize browser", err)
+		s.logger.Println("Unable to open browser tab", err)
 		return &ScrapedProduct{}, err
 	}
 
+	tabContext, cancel := context.WithTimeout(tabContext, time.Duration(s.timeoutInSeconds)*time.Second)
 	defer cancel()
 
-	switch DetectMarketplaceByUrl(url) {
-	case MarketplaceWildberries:
-		return s.scrapeWildberries(url)
-	case MarketplaceOzon:
-		return s.scrapeOzon(url)
+	scraped, err := driver.Scrape(tabContext, s, url)
+
+	if product, ok := scraped.(*ScrapedProduct); ok {
+		product.method = ScrapeMethodBrowser
 	}
 
-	return &ScrapedProduct{}, ErrUnsupported
+	// dead browser is restarted by the pool anyway, page errors (e.g. missing selector) don't need a restart
+	release(ctx.Err() == nil && isBrowserError(err))
+
+	return scraped, err
 }
 
 // Scrape many URLs one by one.
@@ -202,20 +194,7 @@ func (s *Scraper) ScrapeMany(urls []string) ([]ProductDto, error) {
 		return nil, ErrEmptyUrl
 	}
 
-	var cancel context.CancelFunc
-	var err error
-
-	s.ctx, cancel, err = s.newBrowserInstance()
-	if err != nil {
-		s.logger.Println("Unable to initialize browser", err)
-		return nil, err
-	}
-
-	defer cancel()
-
-	var item ProductDto
 	var items []ProductDto
-	var scrapedProduct *ScrapedProduct
 
 	for i, url := range urls {
 		// pause between urls to avoid blocking
@@ -224,16 +203,7 @@ func (s *Scraper) ScrapeMany(urls []string) ([]ProductDto, error) {
 			time.Sleep(2 * time.Second)
 		}
 
-		switch DetectMarketplaceByUrl(url) {
-		case MarketplaceWildberries:
-			item, err = s.scrapeWildberries(url)
-		case MarketplaceOzon:
-			item, err = s.scrapeOzon(url)
-		default:
-			item, err = &ScrapedProduct{}, ErrUnsupported
-		}
-
-		scrapedProduct = item.(*ScrapedProduct)
+		item, err := s.Scrape(url)
 
 		if s.isUnknownError(err) {
 			s.logger.Println("Unknown error while scraping URL:", err)
@@ -250,246 +220,14 @@ func (s *Scraper) ScrapeMany(urls []string) ([]ProductDto, error) {
 			continue
 		}
 
-		s.logger.Println("Found:", scrapedProduct.GetTitle(), " Price:", scrapedProduct.GetCurrentPrice(), "Out of stock?", scrapedProduct.IsOutOfStock())
+		s.logger.Println("Found:", item.GetTitle(), " Price:", item.GetCurrentPrice(), "Out of stock?", item.IsOutOfStock())
 
-		items = append(items, scrapedProduct)
+		items = append(items, item)
 	}
 
 	return items, nil
 }
 
-// Scrape Wildberries.
-func (s *Scraper) scrapeWildberries(url string) (ProductDto, error) {
-	product := &ScrapedProduct{
-		url:         url,
-		marketplace: MarketplaceWildberries,
-	}
-
-	s.logger.Println("Scraping Wildberries URL:", url)
-
-	pageContext, cancel := chromedp.NewContext(s.ctx)
-	defer cancel()
-
-	pageContext, cancel = context.WithTimeout(pageContext, time.Duration(s.timeoutInSeconds)*time.Second)
-	defer cancel()
-
-	err := s.runWithActions(
-		pageContext,
-		chromedp.Navigate(url),
-		chromedp.WaitNotVisible(".general-preloader"),
-
-		// check if error page
-		chromedp.QueryAfter(".content404", func(ctx context.Context, execCtx runtime.ExecutionContextID, nodes ...*cdp.Node) error {
-			if len(nodes) < 1 {
-				return nil
-			}
-
-			return ErrNotFound
-		}, chromedp.ByQuery, chromedp.AtLeast(0)),
-
-		// check if out of stock
-		chromedp.QueryAfter(".product-page", func(ctx context.Context, id runtime.ExecutionContextID, nodes ...*cdp.Node) error {
-			if len(nodes) < 1 {
-				return nil
-			}
-
-			var isOutOfStock bool
-			outOfStockJS := `function () {
-				return (this.querySelector('.sold-out-product') !== null);
-			}`
-
-			s.callFunctionOnNode(ctx, nodes[0], outOfStockJS, &isOutOfStock)
-
-			if !isOutOfStock {
-				return nil
-			}
-
-			var title string
-			titleJS := `function () {
-				return this.querySelector('h1').innerText;
-			}`
-
-			s.callFunctionOnNode(ctx, nodes[0], titleJS, &title)
-
-			title = strings.TrimSpace(title)
-			if title == "" {
-				return ErrNotFound
-			}
-
-			product.title = title
-			product.outOfStock = true
-
-			return ErrOutOfStock
-		}, chromedp.ByQuery, chromedp.AtLeast(0)),
-
-		// get product title
-		chromedp.Text("h1", &product.title, chromedp.ByQuery),
-
-		// get product price
-		chromedp.QueryAfter(".price-block__final-price", func(ctx context.Context, id runtime.ExecutionContextID, nodes ...*cdp.Node) error {
-			if len(nodes) < 1 {
-				return nil
-			}
-
-			var price string
-			priceJS := `function () { 
-				return this.innerHTML;
-			}`
-
-			s.callFunctionOnNode(ctx, nodes[0], priceJS, &price)
-
-			product.price = helpers.CurrencyToMinor(s.parsePrice(price))
-
-			return nil
-		}, chromedp.ByQuery, chromedp.AtLeast(0)),
-
-		// add selected size to the product title (if available)
-		chromedp.QueryAfter(".sizes-list__button.active", func(ctx context.Context, id runtime.ExecutionContextID, nodes ...*cdp.Node) error {
-			if len(nodes) < 1 {
-				return nil
-			}
-
-			var size string
-			sizeJS := `function () {
-				return this.querySelector(".sizes-list__size").innerHTML;
-			}`
-
-			s.callFunctionOnNode(ctx, nodes[0], sizeJS, &size)
-
-			size = strings.TrimSpace(size)
-			if size != "" {
-				product.title = helpers.ConcatStrings(product.title, " ", size)
-			}
-
-			return nil
-		}, chromedp.ByQuery, chromedp.AtLeast(0)),
-	)
-
-	if s.isUnknownError(err) {
-		s.logger.Println("Unknown error while scraping Wildberries URL:", err)
-		return &ScrapedProduct{}, err
-	}
-
-	s.logger.Println("Done scraping Wildberries URL:", url)
-
-	return product, err
-}
-
-// Scrape Ozon.
-func (s *Scraper) scrapeOzon(url string) (ProductDto, error) {
-	product := &ScrapedProduct{
-		url:         url,
-		marketplace: MarketplaceOzon,
-	}
-
-	s.logger.Println("Scraping Ozon URL:", url)
-
-	pageContext, cancel := chromedp.NewContext(s.ctx)
-	defer cancel()
-
-	pageContext, cancel = context.WithTimeout(pageContext, time.Duration(s.timeoutInSeconds)*time.Second)
-	defer cancel()
-
-	err := s.runWithActions(
-		pageContext,
-		chromedp.Navigate(url),
-
-		chromedp.WaitReady("[data-widget=\"container\"]"),
-
-		chromedp.QueryAfter("[data-widget=\"container\"]", func(ctx context.Context, id runtime.ExecutionContextID, nodes ...*cdp.Node) error {
-			// check if error page
-			var hasError bool
-			errorPageJS := `function () {
-				return (this.querySelector('[data-widget="error"]') !== null);
-			}`
-
-			s.callFunctionOnNode(ctx, nodes[0], errorPageJS, &hasError)
-
-			if hasError {
-				return ErrNotFound
-			}
-
-			// check if out of stock
-			var isOutOfStock bool
-			outOfStockJS := `function () {
-				return (this.querySelector('[data-widget="webOutOfStock"]') !== null);
-			}`
-
-			s.callFunctionOnNode(ctx, nodes[0], outOfStockJS, &isOutOfStock)
-
-			if isOutOfStock {
-				var title string
-				titleJS := `function () {
-					return this.querySelector('p').innerText;
-				}`
-
-				s.callFunctionOnNode(ctx, nodes[0], titleJS, &title)
-
-				title = strings.TrimSpace(title)
-				if title == "" {
-					return ErrNotFound
-				}
-
-				product.title = title
-				product.outOfStock = true
-
-				return ErrOutOfStock
-			}
-
-			// scrape product page
-			var title string
-			titleJS := `function () {
-				return this.querySelector('h1').innerText;
-			}`
-
-			s.callFunctionOnNode(ctx, nodes[0], titleJS, &title)
-
-			product.title = strings.TrimSpace(title)
-
-			// there could be "with ozon card" button with "fake" price
-			var price string
-			priceJS := `function () {
-				let nodes = this.querySelector('[data-widget="webPrice"]').querySelectorAll('span:first-of-type');
-
-				return (nodes.length > 1)
-					? nodes[3].innerText
-					: nodes[0].innerText;
-			}`
-
-			s.callFunctionOnNode(ctx, nodes[0], priceJS, &price)
-
-			product.price = helpers.CurrencyToMinor(s.parsePrice(price))
-
-			return nil
-		}, chromedp.ByQuery, chromedp.NodeVisible),
-	)
-
-	if s.isUnknownError(err) {
-		s.logger.Println("Unknown error while scraping Ozon URL:", err)
-		return &ScrapedProduct{}, err
-	}
-
-	s.logger.Println("Done scraping Ozon URL:", url)
-
-	return product, err
-}
-
-// Run browser with actions to scrape product.
-func (s *Scraper) runWithActions(ctx context.Context, actions ...chromedp.Action) error {
-	userAgent := s.randomUserAgent()
-	viewport := s.randomViewport()
-
-	actions = append([]chromedp.Action{
-		chromedpUndetected.UserAgentOverride(userAgent),
-		chromedp.EmulateViewport(int64(viewport.GetWidth()), int64(viewport.GetHeight())),
-	}, actions...)
-
-	return chromedp.Run(
-		ctx,
-		actions...,
-	)
-}
-
 // Check if error is unknown to the system.
 func (s *Scraper) isUnknownError(err error) bool {
 	if err == nil {
@@ -532,6 +270,34 @@ func (s *Scraper) parsePrice(price string) float64 {
 	return math.Round(priceValue*100) / 100
 }
 
+// Request JSON from marketplace API and decode it into target.
+func (s *Scraper) fetchJson(ctx context.Context, url string, target any) error {
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+
+	request.Header.Set("Accept", "application/json")
+	request.Header.Set("User-Agent", randomUserAgent())
+
+	response, err := s.httpClient.Do(request)
+	if err != nil {
+		return err
+	}
+
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return fmt.Errorf("%w: status %d", ErrApiResponse, response.StatusCode)
+	}
+
+	if err := json.NewDecoder(response.Body).Decode(target); err != nil {
+		return fmt.Errorf("%w: %s", ErrApiResponse, err)
+	}
+
+	return nil
+}
+
 // Copy of chromedp.callFunctionOnNode().
 // https://github.com/chromedp/chromedp/blob/master/query.go#L439
 func (s *Scraper) callFunctionOnNode(ctx context.Context, node *cdp.Node, function string, res interface{}, args ...interface{}) error {
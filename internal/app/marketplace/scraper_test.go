@@ -0,0 +1,164 @@
+package marketplace
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+// Expected result of scraping a synthetic HTML page, which mimics markup of marketplace.
+type fixtureCase struct {
+	file       string
+	title      string
+	price      int
+	outOfStock bool
+	err        error
+}
+
+type testLogger struct {
+	t *testing.T
+}
+
+func (l testLogger) Println(message ...any) {
+	l.t.Log(message...)
+}
+
+// Expected result of requesting a synthetic API response, which mimics response of marketplace.
+type apiFixtureCase struct {
+	url        string
+	title      string
+	price      int
+	outOfStock bool
+	err        error
+}
+
+// Serve synthetic API responses from "testdata/<dir>", file is chosen by value of query parameter.
+func newApiTestServer(dir string, param string, files map[string]string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		file, exists := files[r.URL.Query().Get(param)]
+		if !exists {
+			http.NotFound(w, r)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		http.ServeFile(w, r, filepath.Join("testdata", dir, file))
+	}))
+}
+
+// Scrape URLs via API of marketplace, no browser is needed.
+func testScrapeApiFixtures(t *testing.T, marketplace Marketplace, cases []apiFixtureCase) {
+	driver, ok := GetDriver(marketplace)
+	if !ok {
+		t.Fatalf("Driver of marketplace %d is not registered.", marketplace)
+	}
+
+	apiDriver, ok := driver.(ApiDriver)
+	if !ok {
+		t.Fatalf("Driver of marketplace %d doesn't support API.", marketplace)
+	}
+
+	scraper := NewScraper(nil, testLogger{t: t}, 30)
+
+	for _, target := range cases {
+		t.Run(target.url, func(t *testing.T) {
+			result, err := scraper.scrapeWithApi(context.Background(), apiDriver, target.url)
+
+			if !errors.Is(err, target.err) {
+				t.Fatalf("Invalid error, got: %v, instead of: %v.", err, target.err)
+			}
+
+			if target.err != nil && target.err != ErrOutOfStock {
+				return
+			}
+
+			if GetScrapeMethod(result) != ScrapeMethodApi {
+				t.Errorf("Invalid scrape method, got: %d, instead of: %d.", GetScrapeMethod(result), ScrapeMethodApi)
+			}
+
+			if result.GetTitle() != target.title {
+				t.Errorf("Invalid title, got: %q, instead of: %q.", result.GetTitle(), target.title)
+			}
+
+			if result.GetCurrentPrice() != target.price {
+				t.Errorf("Invalid price, got: %d, instead of: %d.", result.GetCurrentPrice(), target.price)
+			}
+
+			if result.IsOutOfStock() != target.outOfStock {
+				t.Errorf("Invalid out of stock state, got: %t, instead of: %t.", result.IsOutOfStock(), target.outOfStock)
+			}
+		})
+	}
+}
+
+// Skip test when there is no browser to scrape with.
+func skipWithoutBrowser(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Browser tests are skipped in short mode.")
+	}
+
+	if _, err := exec.LookPath("Xvfb"); err != nil {
+		t.Skip("Xvfb is not installed.")
+	}
+
+	for _, name := range []string{"google-chrome", "google-chrome-stable", "chromium", "chromium-browser"} {
+		if _, err := exec.LookPath(name); err == nil {
+			return
+		}
+	}
+
+	t.Skip("Chrome is not installed.")
+}
+
+// Serve synthetic pages of marketplace from "testdata/<dir>" and scrape them with its driver.
+func testScrapeFixtures(t *testing.T, marketplace Marketplace, dir string, cases []fixtureCase) {
+	skipWithoutBrowser(t)
+
+	driver, ok := GetDriver(marketplace)
+	if !ok {
+		t.Fatalf("Driver of marketplace %d is not registered.", marketplace)
+	}
+
+	server := httptest.NewServer(http.FileServer(http.Dir(filepath.Join("testdata", dir))))
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	logger := testLogger{t: t}
+
+	pool := NewBrowserPool(ctx, logger, 1, 0)
+	defer pool.Close()
+
+	scraper := NewScraper(pool, logger, 30)
+
+	for _, target := range cases {
+		t.Run(target.file, func(t *testing.T) {
+			result, err := scraper.scrapeWithDriver(ctx, driver, server.URL+"/"+target.file)
+
+			if err != target.err {
+				t.Fatalf("Invalid error, got: %v, instead of: %v.", err, target.err)
+			}
+
+			if target.err == ErrNotFound {
+				return
+			}
+
+			if result.GetTitle() != target.title {
+				t.Errorf("Invalid title, got: %q, instead of: %q.", result.GetTitle(), target.title)
+			}
+
+			if result.GetCurrentPrice() != target.price {
+				t.Errorf("Invalid price, got: %d, instead of: %d.", result.GetCurrentPrice(), target.price)
+			}
+
+			if result.IsOutOfStock() != target.outOfStock {
+				t.Errorf("Invalid out of stock state, got: %t, instead of: %t.", result.IsOutOfStock(), target.outOfStock)
+			}
+		})
+	}
+}
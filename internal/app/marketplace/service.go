@@ -5,6 +5,8 @@ import (
 	"bot/internal/app/helpers"
 	"bot/internal/app/logger"
 	"time"
+
+	"github.com/jackc/pgx/v5"
 )
 
 type ProductDto interface {
@@ -12,41 +14,88 @@ type ProductDto interface {
 	GetSlug() string
 	GetTelegramChatId() int
 	GetTelegramUserId() int
+	GetTelegramUserName() string
 	GetUrl() string
 	GetMarketplace() Marketplace
 	GetTitle() string
 	GetThresholdPrice() int
 	GetCurrentPrice() int
 	IsOutOfStock() bool
+	GetTargetPrice() int
 }
 
+// Methods for user treat zero user id as all users of chat (shared watchlist of group).
 type Repository interface {
 	FindById(id int) (Product, error)
 	FindOutdatedPaginated(offsetInMinutes int, page int, perPage int) []Product
 	GetCountOutdated(offsetInMinutes int) int
 	FindAllForUserPaginated(telegramChatId int, telegramUserId int, page int, perPage int) []Product
 	GetCountForUser(telegramChatId int, telegramUserId int) int
+	FindUnchangedForUser(telegramChatId int, telegramUserId int, since time.Time) []Product
 	FindForUserByUrl(telegramChatId int, telegramUserId int, url string) (Product, error)
 	FindForUserBySlug(telegramChatId int, telegramUserId int, slug string) (Product, error)
 	Delete(id int) bool
+	SetActive(id int, isActive bool) error
+	SetActiveForUser(telegramChatId int, telegramUserId int, isActive bool) (int, error)
+	SetChatBlocked(telegramChatId int, isBlocked bool) (int, error)
 	Save(model Product) (Product, error)
+	SaveWithinTransaction(model Product, callback func(transaction pgx.Tx, model Product) error) (Product, error)
 	IsUniqueSlug(slug string) bool
 }
 
+type PriceHistoryRepository interface {
+	FindForProductInRange(productId int, from time.Time, to time.Time) []PriceObservation
+	Save(transaction pgx.Tx, model PriceObservation) (PriceObservation, error)
+}
+
+type SharedWatchlistRepository interface {
+	IsShared(telegramChatId int) bool
+	SetShared(telegramChatId int, isShared bool) error
+}
+
+type AlertRuleRepository interface {
+	FindForProduct(productId int) []AlertRule
+	ReplaceForProduct(productId int, models []AlertRule) error
+}
+
 const PerPageDefault = 10
 
 type Service struct {
-	repository Repository
-	logger     logger.LoggerInterface
+	repository                Repository
+	historyRepository         PriceHistoryRepository
+	alertRuleRepository       AlertRuleRepository
+	sharedWatchlistRepository SharedWatchlistRepository
+	logger                    logger.LoggerInterface
 }
 
-func NewService(repository Repository, logger logger.LoggerInterface) Service {
+func NewService(repository Repository, historyRepository PriceHistoryRepository, alertRuleRepository AlertRuleRepository, sharedWatchlistRepository SharedWatchlistRepository, logger logger.LoggerInterface) Service {
 	return Service{
-		repository: repository,
-		logger:     logger,
+		repository:                repository,
+		historyRepository:         historyRepository,
+		alertRuleRepository:       alertRuleRepository,
+		sharedWatchlistRepository: sharedWatchlistRepository,
+		logger:                    logger,
 	}
 }
 
+// Check if products of chat are shared by all its members (e.g. in group).
+func (s *Service) IsSharedWatchlist(telegramChatId int) bool {
+	return s.sharedWatchlistRepository.IsShared(telegramChatId)
+}
+
+func (s *Service) SetSharedWatchlist(telegramChatId int, isShared bool) error {
+	return s.sharedWatchlistRepository.SetShared(telegramChatId, isShared)
+}
+
+// Get id of user whose products are shown to the given one, zero means all users of chat (if watchlist is shared).
+func (s *Service) GetWatchlistOwnerId(telegramChatId int, telegramUserId int) int {
+	if s.IsSharedWatchlist(telegramChatId) {
+		return 0
+	}
+
+	return telegramUserId
+}
+
 func (s *Service) FindForUserByUrl(telegramChatId int, telegramUserId int, url string) (Product, error) {
 	return s.repository.FindForUserByUrl(telegramChatId, telegramUserId, url)
 }
@@ -71,6 +120,11 @@ func (s *Service) FindAllForUserPaginated(telegramChatId int, telegramUserId int
 	return core.NewPaginatedResult(items, page, perPage, count)
 }
 
+// Find tracked products of user which price hasn't changed for the given number of days.
+func (s *Service) FindUnchangedForUser(telegramChatId int, telegramUserId int, days int) []Product {
+	return s.repository.FindUnchangedForUser(telegramChatId, telegramUserId, time.Now().AddDate(0, 0, -days))
+}
+
 func (s *Service) FindOutdatedPaginated(outdatedOffsetInMinutes int, page int, perPage int) core.PaginatedResult {
 	if perPage == 0 {
 		perPage = PerPageDefault
@@ -88,18 +142,28 @@ func (s *Service) FindOutdatedPaginated(outdatedOffsetInMinutes int, page int, p
 }
 
 func (s *Service) Create(dto ProductDto) (Product, error) {
+	return s.CreateWithinTransaction(dto, nil)
+}
+
+// Create product, callback is executed within the same transaction (e.g. to write price observation).
+func (s *Service) CreateWithinTransaction(dto ProductDto, callback func(transaction pgx.Tx, model Product) error) (Product, error) {
 	model := Product{}
 
-	return s.updateByDto(model, dto)
+	return s.updateByDto(model, dto, callback)
 }
 
 func (s *Service) Update(id int, dto ProductDto) (Product, error) {
+	return s.UpdateWithinTransaction(id, dto, nil)
+}
+
+// Update product, callback is executed within the same transaction (e.g. to write notification to outbox).
+func (s *Service) UpdateWithinTransaction(id int, dto ProductDto, callback func(transaction pgx.Tx, model Product) error) (Product, error) {
 	model, err := s.repository.FindById(id)
 	if err != nil {
 		return Product{}, err
 	}
 
-	return s.updateByDto(model, dto)
+	return s.updateByDto(model, dto, callback)
 }
 
 func (s *Service) Delete(id int) bool {
@@ -111,22 +175,137 @@ func (s *Service) Delete(id int) bool {
 	return s.repository.Delete(model.Id)
 }
 
-func (s *Service) updateByDto(model Product, dto ProductDto) (Product, error) {
-	model.ScrapedAt = dto.GetScrapedAt()
-	model.TelegramChatId = dto.GetTelegramChatId()
-	model.TelegramUserId = dto.GetTelegramUserId()
-	model.Marketplace = dto.GetMarketplace()
-	model.Url = dto.GetUrl()
-	model.Title = dto.GetTitle()
-	model.ThresholdPrice = dto.GetThresholdPrice()
-	model.CurrentPrice = dto.GetCurrentPrice() // TODO: price history? (https://github.com/timescale/timescaledb)
-	model.OutOfStock = dto.IsOutOfStock()
+// Stop tracking product until it's resumed by user.
+func (s *Service) Pause(id int) error {
+	return s.repository.SetActive(id, false)
+}
+
+// Continue tracking of paused product.
+func (s *Service) Resume(id int) error {
+	return s.repository.SetActive(id, true)
+}
+
+// Stop tracking all products of user, get count of paused products.
+func (s *Service) PauseAllForUser(telegramChatId int, telegramUserId int) (int, error) {
+	return s.repository.SetActiveForUser(telegramChatId, telegramUserId, false)
+}
+
+// Continue tracking all paused products of user, get count of resumed products.
+func (s *Service) ResumeAllForUser(telegramChatId int, telegramUserId int) (int, error) {
+	return s.repository.SetActiveForUser(telegramChatId, telegramUserId, true)
+}
+
+// Stop scraping products of chat, e.g. when user has blocked the bot.
+func (s *Service) PauseForChat(telegramChatId int) error {
+	count, err := s.repository.SetChatBlocked(telegramChatId, true)
+	if err != nil {
+		s.logger.Println("Unable to pause products of chat", telegramChatId, ":", err)
+		return err
+	}
+
+	s.logger.Println("Paused", count, "product(s) of chat", telegramChatId)
+
+	return nil
+}
+
+// Continue scraping products of chat, which were paused by PauseForChat.
+func (s *Service) ResumeForChat(telegramChatId int) error {
+	count, err := s.repository.SetChatBlocked(telegramChatId, false)
+	if err != nil {
+		s.logger.Println("Unable to resume products of chat", telegramChatId, ":", err)
+		return err
+	}
+
+	s.logger.Println("Resumed", count, "product(s) of chat", telegramChatId)
+
+	return nil
+}
+
+// Store scraped price and availability of product as a new history entry within transaction of product update.
+func (s *Service) AddPriceObservation(transaction pgx.Tx, product Product, method ScrapeMethod) (PriceObservation, error) {
+	observation := PriceObservation{
+		ProductId:    product.Id,
+		Marketplace:  product.Marketplace,
+		Price:        product.CurrentPrice,
+		OutOfStock:   product.OutOfStock,
+		ScrapedAt:    product.ScrapedAt,
+		ScrapeMethod: method,
+	}
+
+	if observation.OutOfStock {
+		observation.Price = 0
+	}
+
+	observation, err := s.historyRepository.Save(transaction, observation)
+	if err != nil {
+		s.logger.Println("Unable to save price observation:", err)
+		return PriceObservation{}, err
+	}
+
+	return observation, nil
+}
+
+// Get price history of product within time range (oldest first).
+func (s *Service) GetPriceHistory(productId int, from time.Time, to time.Time) []PriceObservation {
+	return s.historyRepository.FindForProductInRange(productId, from, to)
+}
+
+// Get notification rules of product (the default ones, if product doesn't have its own rules).
+func (s *Service) GetAlertRules(product Product) []AlertRule {
+	rules := s.alertRuleRepository.FindForProduct(product.Id)
+
+	if len(rules) == 0 {
+		return DefaultAlertRules(&product)
+	}
+
+	return rules
+}
+
+// Check if product has its own notification rules.
+func (s *Service) HasOwnAlertRules(product Product) bool {
+	return len(s.alertRuleRepository.FindForProduct(product.Id)) > 0
+}
+
+// Replace notification rules of product, empty list restores the default rules.
+// Price drops of the new rules are measured from the current price.
+func (s *Service) SetAlertRules(product Product, rules []AlertRule) error {
+	if err := s.alertRuleRepository.ReplaceForProduct(product.Id, rules); err != nil {
+		return err
+	}
+
+	if product.OutOfStock || product.CurrentPrice <= 0 || product.ThresholdPrice == product.CurrentPrice {
+		return nil
+	}
+
+	product.ThresholdPrice = product.CurrentPrice
+
+	_, err := s.repository.Save(product)
+
+	return err
+}
+
+// Get rules which are fired by scraped data, it has to be called before scraped data is saved.
+func (s *Service) EvaluateAlertRules(original Product, scraped ProductDto) []AlertRule {
+	rules := s.GetAlertRules(original)
+
+	var history []PriceObservation
+
+	if days := GetAlertRulesHistoryDays(rules); days > 0 {
+		currentTime := time.Now()
+		history = s.GetPriceHistory(original.Id, currentTime.AddDate(0, 0, -days), currentTime)
+	}
+
+	return EvaluateAlertRules(rules, &original, scraped, history)
+}
+
+func (s *Service) updateByDto(model Product, dto ProductDto, callback func(transaction pgx.Tx, model Product) error) (Product, error) {
+	s.fillByDto(&model, dto)
 
 	if model.Slug == "" {
 		model.Slug = s.getUniqueSlug()
 	}
 
-	model, err := s.repository.Save(model)
+	model, err := s.repository.SaveWithinTransaction(model, callback)
 	if err != nil {
 		s.logger.Println("Unable to save model:", err)
 		return Product{}, err
@@ -135,6 +314,20 @@ func (s *Service) updateByDto(model Product, dto ProductDto) (Product, error) {
 	return model, nil
 }
 
+func (s *Service) fillByDto(model *Product, dto ProductDto) {
+	model.ScrapedAt = dto.GetScrapedAt()
+	model.TelegramChatId = dto.GetTelegramChatId()
+	model.TelegramUserId = dto.GetTelegramUserId()
+	model.TelegramUserName = dto.GetTelegramUserName()
+	model.Marketplace = dto.GetMarketplace()
+	model.Url = dto.GetUrl()
+	model.Title = dto.GetTitle()
+	model.ThresholdPrice = dto.GetThresholdPrice()
+	model.CurrentPrice = dto.GetCurrentPrice()
+	model.OutOfStock = dto.IsOutOfStock()
+	model.TargetPrice = dto.GetTargetPrice()
+}
+
 func (s *Service) getUniqueSlug() string {
 	var slug string
 
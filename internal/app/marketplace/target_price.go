@@ -0,0 +1,42 @@
+package marketplace
+
+import (
+	"bot/internal/app/helpers"
+	"errors"
+	"math"
+)
+
+var ErrTargetPriceTooHigh = errors.New("target price is not lower than current price")
+var ErrTargetPriceUnknownBase = errors.New("unable to calculate percentage of unknown price")
+
+// Parse target price from user input, which is either an absolute amount (e.g. "1500")
+// or a percentage below current price (e.g. "10%").
+func ParseTargetPrice(input string, currentPrice int) (int, error) {
+	var targetPrice int
+
+	if helpers.IsPercent(input) {
+		percent, err := helpers.PercentParse(input)
+		if err != nil {
+			return 0, err
+		}
+
+		if currentPrice <= 0 {
+			return 0, ErrTargetPriceUnknownBase
+		}
+
+		targetPrice = int(math.Floor(float64(currentPrice) * (100 - percent) / 100))
+	} else {
+		amount, err := helpers.CurrencyParse(input)
+		if err != nil {
+			return 0, err
+		}
+
+		targetPrice = helpers.CurrencyToMinor(amount)
+	}
+
+	if currentPrice > 0 && targetPrice >= currentPrice {
+		return 0, ErrTargetPriceTooHigh
+	}
+
+	return targetPrice, nil
+}
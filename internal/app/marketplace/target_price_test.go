@@ -0,0 +1,54 @@
+package marketplace_test
+
+import (
+	"bot/internal/app/helpers"
+	"bot/internal/app/marketplace"
+	"testing"
+)
+
+func TestParseTargetPrice(t *testing.T) {
+	currentPrice := 200000
+
+	values := map[string]int{
+		"1500":     150000,
+		"1 999,99": 199999,
+		"10%":      180000,
+		"12.5 %":   175000,
+	}
+
+	for value, target := range values {
+		result, err := marketplace.ParseTargetPrice(value, currentPrice)
+		if err != nil {
+			t.Errorf("Unexpected error for: %s, got: %s.", value, err)
+		}
+
+		if result != target {
+			t.Errorf("Invalid result for: %s, got: %d, instead of: %d.", value, result, target)
+		}
+	}
+}
+
+func TestParseTargetPriceErrors(t *testing.T) {
+	values := map[string]error{
+		"2000":  marketplace.ErrTargetPriceTooHigh,
+		"2500":  marketplace.ErrTargetPriceTooHigh,
+		"abc":   helpers.ErrInvalidAmount,
+		"150%":  helpers.ErrInvalidPercent,
+		"0%":    helpers.ErrInvalidPercent,
+		"-1000": helpers.ErrInvalidAmount,
+	}
+
+	for value, target := range values {
+		if _, err := marketplace.ParseTargetPrice(value, 200000); err != target {
+			t.Errorf("Invalid error for: %s, got: %v, instead of: %s.", value, err, target)
+		}
+	}
+
+	if _, err := marketplace.ParseTargetPrice("10%", 0); err != marketplace.ErrTargetPriceUnknownBase {
+		t.Errorf("Invalid error for unknown current price, got: %v.", err)
+	}
+
+	if result, err := marketplace.ParseTargetPrice("1500", 0); err != nil || result != 150000 {
+		t.Errorf("Invalid result for unknown current price, got: %d (%v).", result, err)
+	}
+}
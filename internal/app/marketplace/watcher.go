@@ -2,110 +2,167 @@ package marketplace
 
 import (
 	"bot/internal/app/logger"
+	"context"
 	"sync"
+	"sync/atomic"
+
+	"github.com/jackc/pgx/v5"
 )
 
 type WatcherResult struct {
-	Original ProductDto
-	Scraped  ProductDto
+	ProductId int
+	Original  ProductDto
+	Scraped   ProductDto
+
+	// Notification rules which are fired by scraped data.
+	Alerts []AlertRule
+}
+
+// Outbox of notifications, watcher result is added within transaction of product update.
+type Outbox interface {
+	AddWatcherResult(transaction pgx.Tx, result WatcherResult) error
 }
 
 type Watcher struct {
-	scraper           Scraper
+	pool              *WorkerPool
 	service           Service
+	outbox            Outbox
 	logger            logger.LoggerInterface
 	locker            sync.Mutex
 	intervalInMinutes int
 }
 
-func NewWatcher(service Service, logger logger.LoggerInterface, intervalInMinutes int, timeout int) Watcher {
+func NewWatcher(service Service, outbox Outbox, pool *WorkerPool, logger logger.LoggerInterface, intervalInMinutes int) Watcher {
 	return Watcher{
-		scraper:           NewScraper(logger, timeout),
+		pool:              pool,
 		service:           service,
+		outbox:            outbox,
 		logger:            logger,
 		intervalInMinutes: intervalInMinutes,
 	}
 }
 
-func (w *Watcher) Run(channel chan<- WatcherResult) error {
+func (w *Watcher) Run(ctx context.Context) error {
 	w.locker.Lock()
 	defer w.locker.Unlock()
 
 	w.logger.Println("Running watcher...")
 
-	scrapedCount := 0
-	page := 1
-
-	result := w.service.FindOutdatedPaginated(w.intervalInMinutes, page, PerPageDefault)
+	products := w.findOutdated()
 
-	if result.Total == 0 {
+	if len(products) == 0 {
 		w.logger.Println("Watcher complete, nothing to scrape")
 		return nil
 	}
 
-	w.logger.Println("Watching", result.Total, "item(s)")
+	w.logger.Println("Watching", len(products), "item(s)")
 
-	pageIterationCount := 0
+	var waitGroup sync.WaitGroup
+	var scrapedCount atomic.Int64
 
-	for {
-		if pageIterationCount == len(result.Items) {
-			pageIterationCount = 0
-			page++
-			result = w.service.FindOutdatedPaginated(w.intervalInMinutes, page, PerPageDefault)
-		}
+	for i, original := range products {
+		w.logger.Println("Item", (i + 1), "-", original.GetUrl())
 
-		if len(result.Items) == 0 {
-			break
-		}
+		waitGroup.Add(1)
 
-		w.logger.Println("Page", page, "/", result.LastPage)
+		task := ScrapeTask{
+			Url: original.Url,
+			Callback: func(scraped ProductDto, err error) {
+				defer waitGroup.Done()
 
-		for i, item := range result.Items {
-			original := item.(Product)
+				w.processResult(original, scraped, err)
+				scrapedCount.Add(1)
+			},
+		}
 
-			w.logger.Println("Item", (i + 1), "-", original.GetUrl())
+		if err := w.pool.Submit(ctx, task); err != nil {
+			waitGroup.Done()
 
-			scraped, err := w.scraper.Scrape(original.Url)
+			if ctx.Err() != nil {
+				break
+			}
 
-			if err != nil && err != ErrOutOfStock {
-				pageIterationCount++
-				scrapedCount++
+			w.logger.Println("Unable to submit scrape task:", err)
+		}
+	}
 
-				w.logger.Println("Unable to scrape:", err)
+	done := make(chan struct{})
 
-				continue
-			}
+	go func() {
+		waitGroup.Wait()
+		close(done)
+	}()
 
-			new := original
+	select {
+	case <-done:
+	case <-ctx.Done():
+		w.logger.Println("Watcher cancelled, scraped", scrapedCount.Load(), "products")
+		return ctx.Err()
+	}
 
-			new.ScrapedAt = scraped.GetScrapedAt()
-			new.OutOfStock = scraped.IsOutOfStock()
+	w.logger.Println("Watcher complete, scraped", scrapedCount.Load(), "products")
 
-			if scraped.GetCurrentPrice() > 0 {
-				new.CurrentPrice = scraped.GetCurrentPrice()
+	return nil
+}
 
-				if new.GetThresholdPrice() != scraped.GetCurrentPrice() {
-					new.ThresholdPrice = scraped.GetCurrentPrice()
-				}
-			}
+// Collect all outdated products before scraping, since scraped ones are no longer outdated.
+func (w *Watcher) findOutdated() []Product {
+	var products []Product
 
-			w.service.Update(original.Id, &new)
+	page := 1
 
-			channel <- WatcherResult{
-				Original: &original,
-				Scraped:  scraped,
-			}
+	for {
+		result := w.service.FindOutdatedPaginated(w.intervalInMinutes, page, PerPageDefault)
 
-			pageIterationCount++
-			scrapedCount++
+		for _, item := range result.Items {
+			products = append(products, item.(Product))
 		}
 
-		if result.IsLastPage() {
+		if len(result.Items) == 0 || result.IsLastPage() {
 			break
 		}
+
+		page++
+	}
+
+	return products
+}
+
+// Save scraped data and pass result to the channel.
+func (w *Watcher) processResult(original Product, scraped ProductDto, err error) {
+	if err != nil && err != ErrOutOfStock {
+		w.logger.Println("Unable to scrape", original.GetUrl(), ":", err)
+		return
 	}
 
-	w.logger.Println("Watcher complete, scraped", scrapedCount, "products")
+	result := WatcherResult{
+		ProductId: original.Id,
+		Original:  &original,
+		Scraped:   scraped,
+		Alerts:    w.service.EvaluateAlertRules(original, scraped),
+	}
 
-	return nil
+	new := original
+
+	new.ScrapedAt = scraped.GetScrapedAt()
+	new.OutOfStock = scraped.IsOutOfStock()
+
+	if scraped.GetCurrentPrice() > 0 {
+		new.CurrentPrice = scraped.GetCurrentPrice()
+	}
+
+	new.ThresholdPrice = GetNextThresholdPrice(&original, scraped, result.Alerts)
+
+	// notification and history are written together with the update, so they're neither lost nor written for unsaved change
+	_, err = w.service.UpdateWithinTransaction(original.Id, &new, func(transaction pgx.Tx, model Product) error {
+		if _, err := w.service.AddPriceObservation(transaction, model, GetScrapeMethod(scraped)); err != nil {
+			return err
+		}
+
+		return w.outbox.AddWatcherResult(transaction, result)
+	})
+
+	if err != nil {
+		w.logger.Println("Unable to update", original.GetUrl(), ":", err)
+	}
 }
@@ -0,0 +1,284 @@
+package marketplace
+
+import (
+	"bot/internal/app/helpers"
+	"context"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/chromedp/cdproto/cdp"
+	"github.com/chromedp/cdproto/runtime"
+	"github.com/chromedp/chromedp"
+)
+
+const MarketplaceWildberries Marketplace = 1
+
+const patternWildberries string = `^(https?://)?(www.)?(wildberries\.ru/catalog/\d+/detail\.aspx\??)(.*&)?(?:targetUrl=[A-Z]+)?(size=\d+)?(&.*)?$`
+
+var regexWildberries = regexp.MustCompile(patternWildberries)
+var regexWildberriesId = regexp.MustCompile(`wildberries\.ru/catalog/(\d+)/`)
+var regexWildberriesSize = regexp.MustCompile(`[?&]size=(\d+)`)
+
+// Product card API, could be replaced in tests.
+var wildberriesApiUrl = "https://card.wb.ru/cards/v2/detail?appkey=1&curr=rub&dest=-1257786"
+
+type wildberriesApiResponse struct {
+	Data struct {
+		Products []struct {
+			Id    int    `json:"id"`
+			Brand string `json:"brand"`
+			Name  string `json:"name"`
+			Sizes []struct {
+				OptionId int    `json:"optionId"`
+				OrigName string `json:"origName"`
+				Price    *struct {
+					Product int `json:"product"`
+				} `json:"price"`
+				Stocks []struct {
+					Qty int `json:"qty"`
+				} `json:"stocks"`
+			} `json:"sizes"`
+		} `json:"products"`
+	} `json:"data"`
+}
+
+type wildberriesDriver struct{}
+
+func init() {
+	RegisterDriver(&wildberriesDriver{})
+}
+
+func (d *wildberriesDriver) GetMarketplace() Marketplace {
+	return MarketplaceWildberries
+}
+
+func (d *wildberriesDriver) GetName() string {
+	return "Wildberries"
+}
+
+func (d *wildberriesDriver) GetKey() string {
+	return "WILDBERRIES"
+}
+
+func (d *wildberriesDriver) IsMatchingUrl(url string) bool {
+	return regexWildberries.MatchString(url)
+}
+
+// Keep only product id and selected size.
+func (d *wildberriesDriver) GetCleanUrl(url string) string {
+	return strings.TrimSuffix(regexWildberries.ReplaceAllString(url, "https://www.$3$5"), "?")
+}
+
+// Scrape product page.
+func (d *wildberriesDriver) Scrape(ctx context.Context, s *Scraper, url string) (ProductDto, error) {
+	product := &ScrapedProduct{
+		url:         url,
+		marketplace: MarketplaceWildberries,
+	}
+
+	s.logger.Println("Scraping Wildberries URL:", url)
+
+	err := chromedp.Run(
+		ctx,
+		chromedp.Navigate(url),
+		chromedp.WaitNotVisible(".general-preloader"),
+
+		// check if error page
+		chromedp.QueryAfter(".content404", func(ctx context.Context, execCtx runtime.ExecutionContextID, nodes ...*cdp.Node) error {
+			if len(nodes) < 1 {
+				return nil
+			}
+
+			return ErrNotFound
+		}, chromedp.ByQuery, chromedp.AtLeast(0)),
+
+		// check if out of stock
+		chromedp.QueryAfter(".product-page", func(ctx context.Context, id runtime.ExecutionContextID, nodes ...*cdp.Node) error {
+			if len(nodes) < 1 {
+				return nil
+			}
+
+			var isOutOfStock bool
+			outOfStockJS := `function () {
+				return (this.querySelector('.sold-out-product') !== null);
+			}`
+
+			s.callFunctionOnNode(ctx, nodes[0], outOfStockJS, &isOutOfStock)
+
+			if !isOutOfStock {
+				return nil
+			}
+
+			title := d.getTitle(ctx, s, nodes[0])
+			if title == "" {
+				return ErrNotFound
+			}
+
+			product.title = title
+			product.outOfStock = true
+
+			return ErrOutOfStock
+		}, chromedp.ByQuery, chromedp.AtLeast(0)),
+
+		// get product title
+		chromedp.QueryAfter(".product-page", func(ctx context.Context, id runtime.ExecutionContextID, nodes ...*cdp.Node) error {
+			if len(nodes) < 1 {
+				return nil
+			}
+
+			product.title = d.getTitle(ctx, s, nodes[0])
+
+			return nil
+		}, chromedp.ByQuery, chromedp.AtLeast(0)),
+
+		// get product price
+		chromedp.QueryAfter(".price-block__final-price", func(ctx context.Context, id runtime.ExecutionContextID, nodes ...*cdp.Node) error {
+			if len(nodes) < 1 {
+				return nil
+			}
+
+			var price string
+			priceJS := `function () { 
+				return this.innerHTML;
+			}`
+
+			s.callFunctionOnNode(ctx, nodes[0], priceJS, &price)
+
+			product.price = helpers.CurrencyToMinor(s.parsePrice(price))
+
+			return nil
+		}, chromedp.ByQuery, chromedp.AtLeast(0)),
+
+		// add selected size to the product title (if available)
+		chromedp.QueryAfter(".sizes-list__button.active", func(ctx context.Context, id runtime.ExecutionContextID, nodes ...*cdp.Node) error {
+			if len(nodes) < 1 {
+				return nil
+			}
+
+			var size string
+			sizeJS := `function () {
+				return this.querySelector(".sizes-list__size").innerHTML;
+			}`
+
+			s.callFunctionOnNode(ctx, nodes[0], sizeJS, &size)
+
+			size = strings.TrimSpace(size)
+			if size != "" {
+				product.title = helpers.ConcatStrings(product.title, " ", size)
+			}
+
+			return nil
+		}, chromedp.ByQuery, chromedp.AtLeast(0)),
+	)
+
+	if s.isUnknownError(err) {
+		s.logger.Println("Unknown error while scraping Wildberries URL:", err)
+		return &ScrapedProduct{}, err
+	}
+
+	s.logger.Println("Done scraping Wildberries URL:", url)
+
+	return product, err
+}
+
+// Get title of product page, brand is shown apart from product name.
+func (d *wildberriesDriver) getTitle(ctx context.Context, s *Scraper, node *cdp.Node) string {
+	var brand, name string
+
+	brandJS := `function () {
+		const brand = this.querySelector('.product-page__header-brand');
+		return brand ? brand.innerText : '';
+	}`
+
+	nameJS := `function () {
+		const name = this.querySelector('h1');
+		return name ? name.innerText : '';
+	}`
+
+	s.callFunctionOnNode(ctx, node, brandJS, &brand)
+	s.callFunctionOnNode(ctx, node, nameJS, &name)
+
+	return getWildberriesTitle(brand, name)
+}
+
+// Get the same title of product from both page and API (e.g. "New Balance Кроссовки 574").
+func getWildberriesTitle(brand string, name string) string {
+	brand = strings.TrimSpace(brand)
+	name = strings.TrimSpace(name)
+
+	if name == "" || brand == "" || strings.Contains(strings.ToLower(name), strings.ToLower(brand)) {
+		return name
+	}
+
+	return helpers.ConcatStrings(brand, " ", name)
+}
+
+// Get product data from card API, price of the selected size is used (or the lowest one among sizes in stock).
+func (d *wildberriesDriver) ScrapeApi(ctx context.Context, s *Scraper, url string) (ProductDto, error) {
+	matches := regexWildberriesId.FindStringSubmatch(url)
+	if matches == nil {
+		return &ScrapedProduct{}, ErrUnsupported
+	}
+
+	s.logger.Println("Requesting Wildberries API:", url)
+
+	var response wildberriesApiResponse
+
+	err := s.fetchJson(ctx, helpers.ConcatStrings(wildberriesApiUrl, "&nm=", matches[1]), &response)
+	if err != nil {
+		return &ScrapedProduct{}, err
+	}
+
+	if len(response.Data.Products) < 1 {
+		return &ScrapedProduct{}, ErrNotFound
+	}
+
+	item := response.Data.Products[0]
+
+	product := &ScrapedProduct{
+		url:         url,
+		marketplace: MarketplaceWildberries,
+		title:       getWildberriesTitle(item.Brand, item.Name),
+		outOfStock:  true,
+	}
+
+	if product.title == "" {
+		return &ScrapedProduct{}, ErrNotFound
+	}
+
+	selectedSize := 0
+	if matches := regexWildberriesSize.FindStringSubmatch(url); matches != nil {
+		selectedSize, _ = strconv.Atoi(matches[1])
+	}
+
+	for _, size := range item.Sizes {
+		if selectedSize > 0 && size.OptionId != selectedSize {
+			continue
+		}
+
+		if selectedSize > 0 && size.OrigName != "" && size.OrigName != "0" {
+			product.title = helpers.ConcatStrings(product.title, " ", size.OrigName)
+		}
+
+		if len(size.Stocks) < 1 || size.Price == nil {
+			continue
+		}
+
+		if product.outOfStock || size.Price.Product < product.price {
+			product.price = size.Price.Product
+		}
+
+		product.outOfStock = false
+	}
+
+	if product.outOfStock {
+		product.price = 0
+
+		return product, ErrOutOfStock
+	}
+
+	s.logger.Println("Done requesting Wildberries API:", url)
+
+	return product, nil
+}
@@ -0,0 +1,80 @@
+package marketplace
+
+import "testing"
+
+func TestWildberriesScrape(t *testing.T) {
+	testScrapeFixtures(t, MarketplaceWildberries, "wildberries", []fixtureCase{
+		{
+			file:  "product.html",
+			title: "Xiaomi Настольная лампа Mi LED Desk Lamp 1S",
+			price: 329900,
+		},
+		{
+			file:  "size_selected.html",
+			title: "New Balance Кроссовки 574 42",
+			price: 874500,
+		},
+		{
+			file:       "out_of_stock.html",
+			title:      "Kitfort Электрический чайник KT-6140",
+			outOfStock: true,
+			err:        ErrOutOfStock,
+		},
+		{
+			file: "not_found.html",
+			err:  ErrNotFound,
+		},
+	})
+}
+
+func TestWildberriesScrapeApi(t *testing.T) {
+	server := newApiTestServer("wildberries", "nm", map[string]string{
+		"111": "api/111.json",
+		"222": "api/222.json",
+		"333": "api/333.json",
+		"444": "api/444.json",
+	})
+	defer server.Close()
+
+	originalUrl := wildberriesApiUrl
+	wildberriesApiUrl = server.URL + "/cards/v2/detail?appkey=1"
+	defer func() { wildberriesApiUrl = originalUrl }()
+
+	testScrapeApiFixtures(t, MarketplaceWildberries, []apiFixtureCase{
+		{
+			url:   "https://www.wildberries.ru/catalog/111/detail.aspx",
+			title: "Xiaomi Настольная лампа Mi LED Desk Lamp 1S",
+			price: 329900,
+		},
+		{
+			url:   "https://www.wildberries.ru/catalog/222/detail.aspx",
+			title: "New Balance Кроссовки 574",
+			price: 799000,
+		},
+		{
+			url:   "https://www.wildberries.ru/catalog/222/detail.aspx?size=2222",
+			title: "New Balance Кроссовки 574 42",
+			price: 874500,
+		},
+		{
+			url:        "https://www.wildberries.ru/catalog/222/detail.aspx?size=2223",
+			title:      "New Balance Кроссовки 574 43",
+			outOfStock: true,
+			err:        ErrOutOfStock,
+		},
+		{
+			url:        "https://www.wildberries.ru/catalog/333/detail.aspx",
+			title:      "Kitfort Электрический чайник KT-6140",
+			outOfStock: true,
+			err:        ErrOutOfStock,
+		},
+		{
+			url: "https://www.wildberries.ru/catalog/444/detail.aspx",
+			err: ErrNotFound,
+		},
+		{
+			url: "https://www.wildberries.ru/catalog/555/detail.aspx",
+			err: ErrApiResponse,
+		},
+	})
+}
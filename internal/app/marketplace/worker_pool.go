@@ -0,0 +1,166 @@
+package marketplace
+
+import (
+	"bot/internal/app/logger"
+	"context"
+	"sync"
+	"time"
+)
+
+const (
+	workerQueueSize = 256
+	workerCooldown  = 2 * time.Second
+)
+
+// Task to scrape a single URL, callback is executed by the worker when scraping is done.
+// Priority tasks (e.g. requested by user) are taken before the regular ones.
+type ScrapeTask struct {
+	Url      string
+	Priority bool
+	Callback func(scraped ProductDto, err error)
+}
+
+type workerQueue struct {
+	priority chan ScrapeTask
+	regular  chan ScrapeTask
+}
+
+type WorkerPool struct {
+	ctx                       context.Context
+	scraper                   Scraper
+	logger                    logger.LoggerInterface
+	concurrencyPerMarketplace int
+	marketplaceConcurrency    map[Marketplace]int
+	slots                     chan struct{}
+	queues                    map[Marketplace]workerQueue
+	locker                    sync.Mutex
+	waitGroup                 sync.WaitGroup
+}
+
+// Create new pool with at most "concurrency" simultaneous scrapes (both via API and browser),
+// and at most "concurrencyPerMarketplace" ones of the same marketplace, unless it's overridden for marketplace.
+// Workers are stopped when context is cancelled.
+func NewWorkerPool(ctx context.Context, scraper Scraper, logger logger.LoggerInterface, concurrency int, concurrencyPerMarketplace int, marketplaceConcurrency map[Marketplace]int) *WorkerPool {
+	if concurrency <= 0 {
+		concurrency = 1
+	}
+
+	if concurrencyPerMarketplace <= 0 {
+		concurrencyPerMarketplace = 1
+	}
+
+	return &WorkerPool{
+		ctx:                       ctx,
+		scraper:                   scraper,
+		logger:                    logger,
+		concurrencyPerMarketplace: concurrencyPerMarketplace,
+		marketplaceConcurrency:    marketplaceConcurrency,
+		slots:                     make(chan struct{}, concurrency),
+		queues:                    make(map[Marketplace]workerQueue),
+	}
+}
+
+// Put task to the queue of its marketplace, blocks while the queue is full.
+func (p *WorkerPool) Submit(ctx context.Context, task ScrapeTask) error {
+	marketplace := DetectMarketplaceByUrl(task.Url)
+	if marketplace == MarketplaceUnknown {
+		return ErrUnsupported
+	}
+
+	queues := p.getQueue(marketplace)
+
+	queue := queues.regular
+	if task.Priority {
+		queue = queues.priority
+	}
+
+	select {
+	case queue <- task:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-p.ctx.Done():
+		return p.ctx.Err()
+	}
+}
+
+// Wait for all workers to stop.
+func (p *WorkerPool) Wait() {
+	p.waitGroup.Wait()
+}
+
+// Get task queue of marketplace, start its workers on first call.
+func (p *WorkerPool) getQueue(marketplace Marketplace) workerQueue {
+	p.locker.Lock()
+	defer p.locker.Unlock()
+
+	queue, exists := p.queues[marketplace]
+	if exists {
+		return queue
+	}
+
+	queue = workerQueue{
+		priority: make(chan ScrapeTask, workerQueueSize),
+		regular:  make(chan ScrapeTask, workerQueueSize),
+	}
+
+	p.queues[marketplace] = queue
+
+	for i := 0; i < p.getConcurrency(marketplace); i++ {
+		p.waitGroup.Add(1)
+
+		go p.work(queue)
+	}
+
+	return queue
+}
+
+// Get max number of simultaneous scrapes of marketplace.
+func (p *WorkerPool) getConcurrency(marketplace Marketplace) int {
+	if concurrency := p.marketplaceConcurrency[marketplace]; concurrency > 0 {
+		return concurrency
+	}
+
+	return p.concurrencyPerMarketplace
+}
+
+// Take tasks from the queue one by one until pool is stopped.
+func (p *WorkerPool) work(queue workerQueue) {
+	defer p.waitGroup.Done()
+
+	for {
+		var task ScrapeTask
+
+		// check priority tasks first, then wait for any
+		select {
+		case task = <-queue.priority:
+		default:
+			select {
+			case <-p.ctx.Done():
+				return
+			case task = <-queue.priority:
+			case task = <-queue.regular:
+			}
+		}
+
+		// wait for free slot of the whole pool
+		select {
+		case <-p.ctx.Done():
+			return
+		case p.slots <- struct{}{}:
+		}
+
+		scraped, err := p.scraper.ScrapeWithContext(p.ctx, task.Url)
+
+		<-p.slots
+
+		task.Callback(scraped, err)
+
+		// pause between urls to avoid blocking
+		select {
+		case <-p.ctx.Done():
+			return
+		case <-time.After(workerCooldown):
+		}
+	}
+}
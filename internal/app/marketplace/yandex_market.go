@@ -0,0 +1,134 @@
+package marketplace
+
+import (
+	"bot/internal/app/helpers"
+	"context"
+	"regexp"
+	"strings"
+
+	"github.com/chromedp/cdproto/cdp"
+	"github.com/chromedp/cdproto/runtime"
+	"github.com/chromedp/chromedp"
+)
+
+const MarketplaceYandexMarket Marketplace = 3
+
+const patternYandexMarket string = `^(https?://)?(www\.)?(market\.yandex\.ru/(?:product--[A-Za-z0-9-]+/\d+|card/[A-Za-z0-9-]+/\d+|product/\d+))(/[a-z-]*)?(\?.*)?$`
+
+var regexYandexMarket = regexp.MustCompile(patternYandexMarket)
+var regexYandexMarketSku = regexp.MustCompile(`[?&]sku=(\d+)`)
+
+type yandexMarketDriver struct{}
+
+func init() {
+	RegisterDriver(&yandexMarketDriver{})
+}
+
+func (d *yandexMarketDriver) GetMarketplace() Marketplace {
+	return MarketplaceYandexMarket
+}
+
+func (d *yandexMarketDriver) GetName() string {
+	return "Яндекс Маркет"
+}
+
+func (d *yandexMarketDriver) GetKey() string {
+	return "YANDEX_MARKET"
+}
+
+func (d *yandexMarketDriver) IsMatchingUrl(url string) bool {
+	return regexYandexMarket.MatchString(url)
+}
+
+// Keep only product path and selected offer (sku).
+func (d *yandexMarketDriver) GetCleanUrl(url string) string {
+	cleanUrl := regexYandexMarket.ReplaceAllString(url, "https://$3")
+
+	if matches := regexYandexMarketSku.FindStringSubmatch(url); matches != nil {
+		cleanUrl = helpers.ConcatStrings(cleanUrl, "?sku=", matches[1])
+	}
+
+	return cleanUrl
+}
+
+// Scrape product page.
+func (d *yandexMarketDriver) Scrape(ctx context.Context, s *Scraper, url string) (ProductDto, error) {
+	product := &ScrapedProduct{
+		url:         url,
+		marketplace: MarketplaceYandexMarket,
+	}
+
+	s.logger.Println("Scraping Yandex Market URL:", url)
+
+	err := chromedp.Run(
+		ctx,
+		chromedp.Navigate(url),
+
+		chromedp.WaitReady("body", chromedp.ByQuery),
+
+		chromedp.QueryAfter("body", func(ctx context.Context, id runtime.ExecutionContextID, nodes ...*cdp.Node) error {
+			// check if error page
+			var hasError bool
+			errorPageJS := `function () {
+				return (this.querySelector('[data-auto="notFound"]') !== null)
+					|| (this.querySelector('h1') === null);
+			}`
+
+			s.callFunctionOnNode(ctx, nodes[0], errorPageJS, &hasError)
+
+			if hasError {
+				return ErrNotFound
+			}
+
+			var title string
+			titleJS := `function () {
+				return this.querySelector('h1').innerText;
+			}`
+
+			s.callFunctionOnNode(ctx, nodes[0], titleJS, &title)
+
+			product.title = strings.TrimSpace(title)
+			if product.title == "" {
+				return ErrNotFound
+			}
+
+			// check if out of stock
+			var isOutOfStock bool
+			outOfStockJS := `function () {
+				return (this.querySelector('[data-auto="soldOut"], [data-auto="outOfStock"]') !== null);
+			}`
+
+			s.callFunctionOnNode(ctx, nodes[0], outOfStockJS, &isOutOfStock)
+
+			if isOutOfStock {
+				product.outOfStock = true
+
+				return ErrOutOfStock
+			}
+
+			// main price goes first, there could be also "with Yandex Pay" and old (crossed out) prices
+			var price string
+			priceJS := `function () {
+				let node = this.querySelector('[data-auto="snippet-price-current"]')
+					|| this.querySelector('[data-auto="price-value"]');
+
+				return (node !== null) ? node.innerText : '';
+			}`
+
+			s.callFunctionOnNode(ctx, nodes[0], priceJS, &price)
+
+			product.price = helpers.CurrencyToMinor(s.parsePrice(price))
+
+			return nil
+		}, chromedp.ByQuery, chromedp.NodeReady),
+	)
+
+	if s.isUnknownError(err) {
+		s.logger.Println("Unknown error while scraping Yandex Market URL:", err)
+		return &ScrapedProduct{}, err
+	}
+
+	s.logger.Println("Done scraping Yandex Market URL:", url)
+
+	return product, err
+}
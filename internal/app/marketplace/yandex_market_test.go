@@ -0,0 +1,23 @@
+package marketplace
+
+import "testing"
+
+func TestYandexMarketScrape(t *testing.T) {
+	testScrapeFixtures(t, MarketplaceYandexMarket, "yandex_market", []fixtureCase{
+		{
+			file:  "product.html",
+			title: "Смартфон Apple iPhone 15 128 ГБ, черный",
+			price: 7249900,
+		},
+		{
+			file:       "out_of_stock.html",
+			title:      "Наушники Sony WH-1000XM5, черный",
+			outOfStock: true,
+			err:        ErrOutOfStock,
+		},
+		{
+			file: "not_found.html",
+			err:  ErrNotFound,
+		},
+	})
+}
@@ -0,0 +1,82 @@
+package notification
+
+import (
+	"bot/internal/app/helpers"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// Max length of Telegram message text.
+const MessageMaxLength = 4096
+
+// Check if it's time to send digest to user: the scheduled hour (of Monday for weekly digest) has come,
+// and digest hasn't been sent since then.
+func IsDigestDue(settings UserSettings, now time.Time, defaultLocation *time.Location) bool {
+	if !settings.IsDigestEnabled() {
+		return false
+	}
+
+	localNow := now.In(settings.GetLocation(defaultLocation))
+	scheduledAt := time.Date(localNow.Year(), localNow.Month(), localNow.Day(), settings.DigestHour, 0, 0, 0, localNow.Location())
+
+	if scheduledAt.After(localNow) {
+		scheduledAt = scheduledAt.AddDate(0, 0, -1)
+	}
+
+	if settings.DigestMode == DigestModeWeekly {
+		daysSinceMonday := (int(scheduledAt.Weekday()) + 6) % 7
+		scheduledAt = scheduledAt.AddDate(0, 0, -daysSinceMonday)
+	}
+
+	return settings.DigestSentAt == nil || settings.DigestSentAt.Before(scheduledAt)
+}
+
+// Message of digest, it contains the next "EntriesCount" entries after the previous page.
+type DigestPage struct {
+	Text         string
+	EntriesCount int
+}
+
+// Split digest into pages which fit the message length limit (entries are never split),
+// header is repeated on each page with page number.
+func SplitDigestPages(header string, entries []string, maxLength int) []DigestPage {
+	// reserve space for page number, e.g. " (2/3)"
+	const pageNumberLength = 12
+
+	var pages []DigestPage
+
+	bodyMaxLength := maxLength - utf8.RuneCountInString(header) - pageNumberLength - 1
+	page := DigestPage{}
+
+	for _, entry := range entries {
+		if page.Text != "" && utf8.RuneCountInString(page.Text)+utf8.RuneCountInString(entry)+1 > bodyMaxLength {
+			pages = append(pages, page)
+			page = DigestPage{}
+		}
+
+		if page.Text != "" {
+			page.Text = helpers.ConcatStrings(page.Text, "\n")
+		}
+
+		page.Text = helpers.ConcatStrings(page.Text, entry)
+		page.EntriesCount++
+	}
+
+	if page.Text != "" {
+		pages = append(pages, page)
+	}
+
+	for i := range pages {
+		pageHeader := header
+
+		if len(pages) > 1 {
+			pageHeader = helpers.ConcatStrings(header, " (", strconv.Itoa(i+1), "/", strconv.Itoa(len(pages)), ")")
+		}
+
+		pages[i].Text = strings.Join([]string{pageHeader, pages[i].Text}, "\n")
+	}
+
+	return pages
+}
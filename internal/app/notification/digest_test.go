@@ -0,0 +1,125 @@
+package notification_test
+
+import (
+	"bot/internal/app/notification"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+)
+
+func TestIsDigestDue(t *testing.T) {
+	location := time.FixedZone("UTC+3", 3*60*60)
+
+	// Wednesday, 10:30 of local time
+	now := time.Date(2026, 10, 14, 7, 30, 0, 0, time.UTC)
+
+	sentAt := func(year int, month time.Month, day int, hour int) *time.Time {
+		value := time.Date(year, month, day, hour, 0, 0, 0, location)
+		return &value
+	}
+
+	cases := map[string]struct {
+		settings notification.UserSettings
+		isDue    bool
+	}{
+		"disabled": {
+			settings: notification.UserSettings{DigestHour: 9},
+			isDue:    false,
+		},
+		"never sent": {
+			settings: notification.UserSettings{DigestMode: notification.DigestModeDaily, DigestHour: 9},
+			isDue:    true,
+		},
+		"sent yesterday": {
+			settings: notification.UserSettings{DigestMode: notification.DigestModeDaily, DigestHour: 9, DigestSentAt: sentAt(2026, 10, 13, 9)},
+			isDue:    true,
+		},
+		"sent today": {
+			settings: notification.UserSettings{DigestMode: notification.DigestModeDaily, DigestHour: 9, DigestSentAt: sentAt(2026, 10, 14, 9)},
+			isDue:    false,
+		},
+		"hour hasn't come": {
+			settings: notification.UserSettings{DigestMode: notification.DigestModeDaily, DigestHour: 12, DigestSentAt: sentAt(2026, 10, 13, 12)},
+			isDue:    false,
+		},
+		"weekly sent on Monday": {
+			settings: notification.UserSettings{DigestMode: notification.DigestModeWeekly, DigestHour: 9, DigestSentAt: sentAt(2026, 10, 12, 9)},
+			isDue:    false,
+		},
+		"weekly sent last week": {
+			settings: notification.UserSettings{DigestMode: notification.DigestModeWeekly, DigestHour: 9, DigestSentAt: sentAt(2026, 10, 5, 9)},
+			isDue:    true,
+		},
+		"user timezone": {
+			settings: notification.UserSettings{DigestMode: notification.DigestModeDaily, DigestHour: 9, Timezone: "UTC", DigestSentAt: sentAt(2026, 10, 13, 12)},
+			isDue:    false,
+		},
+	}
+
+	for name, c := range cases {
+		if isDue := notification.IsDigestDue(c.settings, now, location); isDue != c.isDue {
+			t.Errorf("Invalid due check for: %s, got: %v, instead of: %v.", name, isDue, c.isDue)
+		}
+	}
+}
+
+func TestSplitDigestPages(t *testing.T) {
+	pages := notification.SplitDigestPages("Digest", []string{"first", "second"}, notification.MessageMaxLength)
+
+	if len(pages) != 1 || pages[0].Text != "Digest\nfirst\nsecond" || pages[0].EntriesCount != 2 {
+		t.Errorf("Invalid pages, got: %+v, instead of: %q.", pages, []string{"Digest\nfirst\nsecond"})
+	}
+
+	var entries []string
+
+	for range 100 {
+		entries = append(entries, strings.Repeat("ы", 100))
+	}
+
+	pages = notification.SplitDigestPages("Digest", entries, notification.MessageMaxLength)
+
+	if len(pages) != 3 {
+		t.Fatalf("Invalid pages count, got: %d, instead of: %d.", len(pages), 3)
+	}
+
+	entriesCount := 0
+
+	for i, page := range pages {
+		if utf8.RuneCountInString(page.Text) > notification.MessageMaxLength {
+			t.Errorf("Invalid page length, got: %d, instead of: <= %d.", utf8.RuneCountInString(page.Text), notification.MessageMaxLength)
+		}
+
+		if !strings.HasPrefix(page.Text, "Digest (") {
+			t.Errorf("Invalid page %d header, got: %q.", i+1, strings.SplitN(page.Text, "\n", 2)[0])
+		}
+
+		if strings.Count(page.Text, "\n") != page.EntriesCount {
+			t.Errorf("Invalid page %d entries count, got: %d, instead of: %d.", i+1, page.EntriesCount, strings.Count(page.Text, "\n"))
+		}
+
+		entriesCount += page.EntriesCount
+	}
+
+	if entriesCount != len(entries) {
+		t.Errorf("Invalid entries count, got: %d, instead of: %d.", entriesCount, len(entries))
+	}
+
+	if !strings.HasPrefix(pages[2].Text, "Digest (3/3)\n") {
+		t.Errorf("Invalid last page header, got: %q, instead of: %q.", strings.SplitN(pages[2].Text, "\n", 2)[0], "Digest (3/3)")
+	}
+}
+
+func TestGetNextDigestMode(t *testing.T) {
+	values := map[notification.DigestMode]notification.DigestMode{
+		notification.DigestModeOff:    notification.DigestModeDaily,
+		notification.DigestModeDaily:  notification.DigestModeWeekly,
+		notification.DigestModeWeekly: notification.DigestModeOff,
+	}
+
+	for mode, expected := range values {
+		if got := notification.GetNextDigestMode(mode); got != expected {
+			t.Errorf("Invalid next mode for: %q, got: %q, instead of: %q.", mode, got, expected)
+		}
+	}
+}
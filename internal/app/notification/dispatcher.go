@@ -0,0 +1,98 @@
+package notification
+
+import (
+	"bot/internal/app/logger"
+	"context"
+	"errors"
+	"time"
+)
+
+const (
+	// Number of delivery attempts before notification is dead.
+	DeliveryMaxAttempts = 5
+
+	// How long claimed notification is not given to other dispatchers.
+	deliveryLease = 5 * time.Minute
+
+	dispatcherPollInterval = 10 * time.Second
+	dispatcherBatchSize    = 10
+
+	// Rest of the batch is left for the next claim if its lease is about to expire.
+	leaseSafetyMargin = time.Minute
+
+	retryDelayMin = time.Minute
+	retryDelayMax = time.Hour
+)
+
+// Sending can't succeed (e.g. user has blocked the bot), so notification is dead without retries.
+var ErrUndeliverable = errors.New("notification is undeliverable")
+
+// Sends notifications from outbox, each one is marked as sent only after successful delivery.
+type Dispatcher struct {
+	service *Service
+	logger  logger.LoggerInterface
+}
+
+func NewDispatcher(service *Service, logger logger.LoggerInterface) *Dispatcher {
+	return &Dispatcher{
+		service: service,
+		logger:  logger,
+	}
+}
+
+// Send due notifications until context is done.
+func (d *Dispatcher) Run(ctx context.Context, send func(notification Notification) error) {
+	for {
+		d.dispatch(ctx, send)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(dispatcherPollInterval):
+		}
+	}
+}
+
+func (d *Dispatcher) dispatch(ctx context.Context, send func(notification Notification) error) {
+	for ctx.Err() == nil {
+		claimedAt := time.Now()
+		notifications := d.service.ClaimDue(dispatcherBatchSize)
+
+		for _, notification := range notifications {
+			// otherwise another dispatcher could claim and send it too
+			if time.Since(claimedAt) > deliveryLease-leaseSafetyMargin {
+				d.logger.Println("Lease of notifications is about to expire, the rest of batch is postponed")
+				return
+			}
+
+			if err := send(notification); err != nil {
+				d.logger.Println("Unable to send notification", notification.Id, ":", err)
+
+				if err := d.service.MarkFailed(notification, err); err != nil {
+					d.logger.Println("Unable to mark notification as failed:", err)
+				}
+
+				continue
+			}
+
+			if err := d.service.MarkSent([]Notification{notification}); err != nil {
+				d.logger.Println("Unable to mark notification as sent:", err)
+			}
+		}
+
+		if len(notifications) < dispatcherBatchSize {
+			return
+		}
+	}
+}
+
+// Get delay before the next delivery attempt, it's doubled after each failed attempt.
+func GetRetryDelay(attempts int) time.Duration {
+	delay := retryDelayMin
+
+	for i := 1; i < attempts && delay < retryDelayMax; i++ {
+		delay *= 2
+	}
+
+	return min(delay, retryDelayMax)
+}
@@ -0,0 +1,123 @@
+package notification
+
+import (
+	"bot/internal/app/core"
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+type fakeNotificationRepository struct {
+	due    []Notification
+	sent   []int
+	failed []Notification
+}
+
+func (r *fakeNotificationRepository) FindPendingForUser(telegramChatId int, telegramUserId int) []Notification {
+	return nil
+}
+
+func (r *fakeNotificationRepository) ClaimDue(now time.Time, leaseUntil time.Time, limit int) []Notification {
+	due := r.due[:min(limit, len(r.due))]
+	r.due = r.due[len(due):]
+
+	return due
+}
+
+func (r *fakeNotificationRepository) FindDead(limit int) []Notification {
+	return nil
+}
+
+func (r *fakeNotificationRepository) GetCountByStatus() (map[NotificationStatus]int, error) {
+	return nil, nil
+}
+
+func (r *fakeNotificationRepository) Create(transaction pgx.Tx, model Notification) (Notification, error) {
+	return model, nil
+}
+
+func (r *fakeNotificationRepository) MarkSent(ids []int) error {
+	r.sent = append(r.sent, ids...)
+	return nil
+}
+
+func (r *fakeNotificationRepository) MarkFailed(model Notification) error {
+	r.failed = append(r.failed, model)
+	return nil
+}
+
+type fakeLogger struct{}
+
+func (l fakeLogger) Println(message ...any) {}
+
+func TestDispatcherDispatch(t *testing.T) {
+	repository := &fakeNotificationRepository{
+		due: []Notification{
+			{Model: core.Model{Id: 1}, Status: NotificationStatusPending},
+			{Model: core.Model{Id: 2}, Status: NotificationStatusPending},
+			{Model: core.Model{Id: 3}, Status: NotificationStatusPending, Attempts: DeliveryMaxAttempts - 1},
+			{Model: core.Model{Id: 4}, Status: NotificationStatusPending},
+		},
+	}
+
+	service := NewService(nil, repository, fakeLogger{})
+	dispatcher := NewDispatcher(&service, fakeLogger{})
+
+	dispatcher.dispatch(context.Background(), func(notification Notification) error {
+		switch notification.Id {
+		case 2, 3:
+			return errors.New("connection refused")
+		case 4:
+			return fmt.Errorf("%w: %s", ErrUndeliverable, "Forbidden: bot was blocked by the user")
+		}
+
+		return nil
+	})
+
+	if len(repository.sent) != 1 || repository.sent[0] != 1 {
+		t.Errorf("Invalid sent notifications, got: %v, instead of: %v.", repository.sent, []int{1})
+	}
+
+	statuses := map[int]NotificationStatus{
+		2: NotificationStatusPending,
+		3: NotificationStatusDead,
+		4: NotificationStatusDead,
+	}
+
+	if len(repository.failed) != len(statuses) {
+		t.Fatalf("Invalid failed notifications count, got: %d, instead of: %d.", len(repository.failed), len(statuses))
+	}
+
+	for _, failed := range repository.failed {
+		if failed.Status != statuses[failed.Id] {
+			t.Errorf("Invalid status of notification %d, got: %s, instead of: %s.", failed.Id, failed.Status, statuses[failed.Id])
+		}
+
+		if failed.Error == "" {
+			t.Errorf("Error of notification %d is not saved.", failed.Id)
+		}
+	}
+
+	if repository.failed[0].Attempts != 1 || !repository.failed[0].NextAttemptAt.After(time.Now()) {
+		t.Errorf("Invalid retry of notification, got: %d attempts at %v.", repository.failed[0].Attempts, repository.failed[0].NextAttemptAt)
+	}
+}
+
+func TestGetRetryDelay(t *testing.T) {
+	values := map[int]time.Duration{
+		1:  time.Minute,
+		2:  2 * time.Minute,
+		3:  4 * time.Minute,
+		10: time.Hour,
+	}
+
+	for attempts, expected := range values {
+		if delay := GetRetryDelay(attempts); delay != expected {
+			t.Errorf("Invalid retry delay for: %d, got: %v, instead of: %v.", attempts, delay, expected)
+		}
+	}
+}
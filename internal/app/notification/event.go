@@ -0,0 +1,33 @@
+package notification
+
+import "bot/internal/app/marketplace"
+
+type EventType string
+
+const (
+	EventPriceIncrease EventType = "price_increase"
+	EventSoldOut       EventType = "sold_out"
+)
+
+// Get events of scraped product which are not covered by alert rules, but user has opted in for.
+func DetectOptInEvents(settings UserSettings, original marketplace.ProductDto, scraped marketplace.ProductDto) []EventType {
+	var events []EventType
+
+	if original.IsOutOfStock() {
+		return events
+	}
+
+	if scraped.IsOutOfStock() {
+		if settings.NotifySoldOut {
+			events = append(events, EventSoldOut)
+		}
+
+		return events
+	}
+
+	if settings.NotifyPriceIncrease && original.GetCurrentPrice() > 0 && scraped.GetCurrentPrice() > original.GetCurrentPrice() {
+		events = append(events, EventPriceIncrease)
+	}
+
+	return events
+}
@@ -0,0 +1,83 @@
+package notification_test
+
+import (
+	"bot/internal/app/marketplace"
+	"bot/internal/app/notification"
+	"testing"
+)
+
+func TestDetectOptInEvents(t *testing.T) {
+	all := notification.UserSettings{NotifyPriceIncrease: true, NotifySoldOut: true}
+	none := notification.UserSettings{}
+
+	cases := map[string]struct {
+		settings notification.UserSettings
+		original *marketplace.Product
+		scraped  *marketplace.Product
+		events   []notification.EventType
+	}{
+		"price increase": {
+			settings: all,
+			original: &marketplace.Product{CurrentPrice: 100000},
+			scraped:  &marketplace.Product{CurrentPrice: 110000},
+			events:   []notification.EventType{notification.EventPriceIncrease},
+		},
+		"price increase without opt-in": {
+			settings: none,
+			original: &marketplace.Product{CurrentPrice: 100000},
+			scraped:  &marketplace.Product{CurrentPrice: 110000},
+		},
+		"price drop": {
+			settings: all,
+			original: &marketplace.Product{CurrentPrice: 100000},
+			scraped:  &marketplace.Product{CurrentPrice: 90000},
+		},
+		"sold out": {
+			settings: all,
+			original: &marketplace.Product{CurrentPrice: 100000},
+			scraped:  &marketplace.Product{OutOfStock: true},
+			events:   []notification.EventType{notification.EventSoldOut},
+		},
+		"sold out without opt-in": {
+			settings: none,
+			original: &marketplace.Product{CurrentPrice: 100000},
+			scraped:  &marketplace.Product{OutOfStock: true},
+		},
+		"still sold out": {
+			settings: all,
+			original: &marketplace.Product{CurrentPrice: 100000, OutOfStock: true},
+			scraped:  &marketplace.Product{OutOfStock: true},
+		},
+	}
+
+	for name, c := range cases {
+		events := notification.DetectOptInEvents(c.settings, c.original, c.scraped)
+
+		if len(events) != len(c.events) {
+			t.Errorf("Invalid events for: %s, got: %v, instead of: %v.", name, events, c.events)
+			continue
+		}
+
+		for i, event := range events {
+			if event != c.events[i] {
+				t.Errorf("Invalid event for: %s, got: %s, instead of: %s.", name, event, c.events[i])
+			}
+		}
+	}
+}
+
+func TestGetNextUnchangedDigestDays(t *testing.T) {
+	values := map[int]int{
+		0:  7,
+		7:  14,
+		14: 30,
+		30: 0,
+		5:  0,
+	}
+
+	for days, expected := range values {
+		if got := notification.GetNextUnchangedDigestDays(days); got != expected {
+			t.Errorf("Invalid next option for: %d, got: %d, instead of: %d.", days, got, expected)
+		}
+	}
+}
@@ -0,0 +1,128 @@
+package notification
+
+import (
+	"bot/internal/app/core"
+	"bot/internal/app/marketplace"
+	"time"
+)
+
+type DigestMode string
+
+const (
+	DigestModeOff    DigestMode = ""
+	DigestModeDaily  DigestMode = "daily"
+	DigestModeWeekly DigestMode = "weekly"
+)
+
+// Notification preferences of user, all of them are opt-in.
+type UserSettings struct {
+	core.Model
+	TelegramChatId      int
+	TelegramUserId      int
+	NotifyPriceIncrease bool
+	NotifySoldOut       bool
+
+	// Send a digest of products which price hasn't changed for the given number of days (disabled if zero).
+	UnchangedDigestDays   int
+	UnchangedDigestSentAt *time.Time
+	UpdatedAt             time.Time
+
+	// Accumulate notifications and send them as a digest at the given hour (local time of user).
+	DigestMode   DigestMode
+	DigestHour   int
+	Timezone     string
+	DigestSentAt *time.Time
+}
+
+func (s *UserSettings) IsDigestEnabled() bool {
+	return s.DigestMode != DigestModeOff
+}
+
+// Get time location of user, the default one is used if user hasn't set a valid timezone.
+func (s *UserSettings) GetLocation(defaultLocation *time.Location) *time.Location {
+	if s.Timezone == "" {
+		return defaultLocation
+	}
+
+	location, err := time.LoadLocation(s.Timezone)
+	if err != nil {
+		return defaultLocation
+	}
+
+	return location
+}
+
+type NotificationStatus string
+
+const (
+	NotificationStatusPending NotificationStatus = "pending"
+	NotificationStatusSent    NotificationStatus = "sent"
+
+	// Delivery has failed too many times (or can't succeed at all).
+	NotificationStatusDead NotificationStatus = "dead"
+)
+
+// Change of tracked product which user is notified about (instantly or in digest).
+type Notification struct {
+	core.Model
+	TelegramChatId int
+	TelegramUserId int
+	ProductId      int
+	Url            string
+	Marketplace    marketplace.Marketplace
+	Title          string
+	OldPrice       int
+	NewPrice       int
+	TargetPrice    int
+	OutOfStock     bool
+
+	// Notification rules which are fired, or opt-in event if there are no such rules.
+	Alerts []marketplace.AlertRule
+	Event  EventType
+
+	CreatedAt time.Time
+	SentAt    *time.Time
+
+	Status        NotificationStatus
+	Attempts      int
+	NextAttemptAt time.Time
+	Error         string
+
+	// Name of user who has added the product, it's mentioned in notifications of group.
+	TelegramUserName string
+}
+
+// Create notification of product change found by watcher.
+func NewNotification(result marketplace.WatcherResult, event EventType) Notification {
+	return Notification{
+		TelegramChatId:   result.Original.GetTelegramChatId(),
+		TelegramUserId:   result.Original.GetTelegramUserId(),
+		TelegramUserName: result.Original.GetTelegramUserName(),
+		ProductId:        result.ProductId,
+		Url:              result.Original.GetUrl(),
+		Marketplace:      result.Original.GetMarketplace(),
+		Title:            result.Original.GetTitle(),
+		OldPrice:         result.Original.GetThresholdPrice(),
+		NewPrice:         result.Scraped.GetCurrentPrice(),
+		TargetPrice:      result.Original.GetTargetPrice(),
+		OutOfStock:       result.Scraped.IsOutOfStock(),
+		Alerts:           result.Alerts,
+		Event:            event,
+		Status:           NotificationStatusPending,
+	}
+}
+
+// Get product data of the moment when notification is created.
+func (n *Notification) GetProduct() marketplace.Product {
+	return marketplace.Product{
+		TelegramChatId: n.TelegramChatId,
+		TelegramUserId: n.TelegramUserId,
+		Url:            n.Url,
+		Marketplace:    n.Marketplace,
+		Title:          n.Title,
+		ThresholdPrice: n.OldPrice,
+		CurrentPrice:   n.NewPrice,
+		TargetPrice:    n.TargetPrice,
+		OutOfStock:     n.OutOfStock,
+	}
+}
@@ -0,0 +1,263 @@
+package notification
+
+import (
+	"bot/internal/app/database"
+	"bot/internal/app/helpers"
+	"bot/internal/app/logger"
+	"bot/internal/app/marketplace"
+	"slices"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+type PostgresNotificationRepository struct {
+	db     *database.Postgres
+	logger logger.LoggerInterface
+}
+
+func NewPostgresNotificationRepository(db *database.Postgres, logger logger.LoggerInterface) PostgresNotificationRepository {
+	return PostgresNotificationRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// Find notifications of user which are not sent yet (oldest first).
+func (r *PostgresNotificationRepository) FindPendingForUser(telegramChatId int, telegramUserId int) []Notification {
+	sql := "SELECT * FROM notifications" +
+		" WHERE telegram_chat_id = @telegram_chat_id AND telegram_user_id = @telegram_user_id AND status = @pending" +
+		" ORDER BY created_at, id"
+
+	args := pgx.NamedArgs{
+		"telegram_chat_id": telegramChatId,
+		"telegram_user_id": telegramUserId,
+		"pending":          NotificationStatusPending,
+	}
+
+	return r.fetchModels(sql, args)
+}
+
+// Claim pending notifications which are due for instant delivery (users with digest get them later),
+// claimed ones are not due again until the lease is over, so they are not sent twice by concurrent dispatchers.
+func (r *PostgresNotificationRepository) ClaimDue(now time.Time, leaseUntil time.Time, limit int) []Notification {
+	sql := "UPDATE notifications SET next_attempt_at = @lease_until" +
+		" WHERE id IN (" +
+		" SELECT n.id FROM notifications n" +
+		" WHERE n.status = @pending AND n.next_attempt_at <= @now" +
+		" AND NOT EXISTS (" +
+		" SELECT 1 FROM user_settings s" +
+		" WHERE s.telegram_chat_id = n.telegram_chat_id AND s.telegram_user_id = n.telegram_user_id AND s.digest_mode <> @digest_off" +
+		")" +
+		" ORDER BY n.next_attempt_at, n.id" +
+		" LIMIT @limit" +
+		" FOR UPDATE OF n SKIP LOCKED" +
+		")" +
+		" RETURNING *"
+
+	args := pgx.NamedArgs{
+		"now":         helpers.TimeToDatabase(now),
+		"lease_until": helpers.TimeToDatabase(leaseUntil),
+		"pending":     NotificationStatusPending,
+		"digest_off":  DigestModeOff,
+		"limit":       limit,
+	}
+
+	models := r.fetchModels(sql, args)
+
+	slices.SortFunc(models, func(a Notification, b Notification) int {
+		return a.Id - b.Id
+	})
+
+	return models
+}
+
+// Find notifications which are not delivered (newest first).
+func (r *PostgresNotificationRepository) FindDead(limit int) []Notification {
+	sql := "SELECT * FROM notifications WHERE status = @dead ORDER BY id DESC LIMIT @limit"
+
+	args := pgx.NamedArgs{
+		"dead":  NotificationStatusDead,
+		"limit": limit,
+	}
+
+	return r.fetchModels(sql, args)
+}
+
+// Get count of notifications by status.
+func (r *PostgresNotificationRepository) GetCountByStatus() (map[NotificationStatus]int, error) {
+	rows, err := r.db.Connection.Query(r.db.Context, "SELECT status, COUNT(*) FROM notifications GROUP BY status")
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	counts := map[NotificationStatus]int{}
+
+	for rows.Next() {
+		var status NotificationStatus
+		var count int
+
+		if err := rows.Scan(&status, &count); err != nil {
+			return nil, err
+		}
+
+		counts[status] = count
+	}
+
+	return counts, rows.Err()
+}
+
+// Create notification within the given transaction (e.g. together with product update).
+func (r *PostgresNotificationRepository) Create(transaction pgx.Tx, model Notification) (Notification, error) {
+	sql := `INSERT INTO notifications (
+		telegram_chat_id,
+		telegram_user_id,
+		product_id,
+		url,
+		marketplace,
+		title,
+		old_price,
+		new_price,
+		target_price,
+		out_of_stock,
+		alerts,
+		event,
+		created_at,
+		status,
+		next_attempt_at,
+		telegram_user_name
+	) VALUES (
+		@telegram_chat_id,
+		@telegram_user_id,
+		@product_id,
+		@url,
+		@marketplace,
+		@title,
+		@old_price,
+		@new_price,
+		@target_price,
+		@out_of_stock,
+		@alerts,
+		@event,
+		@created_at,
+		@status,
+		@next_attempt_at,
+		@telegram_user_name
+	) RETURNING id`
+
+	model.CreatedAt = time.Now()
+	model.Status = NotificationStatusPending
+	model.NextAttemptAt = model.CreatedAt
+
+	if model.Alerts == nil {
+		model.Alerts = []marketplace.AlertRule{}
+	}
+
+	args := pgx.NamedArgs{
+		"telegram_chat_id":   model.TelegramChatId,
+		"telegram_user_id":   model.TelegramUserId,
+		"product_id":         model.ProductId,
+		"url":                model.Url,
+		"marketplace":        model.Marketplace,
+		"title":              model.Title,
+		"old_price":          model.OldPrice,
+		"new_price":          model.NewPrice,
+		"target_price":       model.TargetPrice,
+		"out_of_stock":       model.OutOfStock,
+		"alerts":             model.Alerts,
+		"event":              model.Event,
+		"created_at":         helpers.TimeToDatabase(model.CreatedAt),
+		"status":             model.Status,
+		"next_attempt_at":    helpers.TimeToDatabase(model.NextAttemptAt),
+		"telegram_user_name": model.TelegramUserName,
+	}
+
+	err := transaction.QueryRow(r.db.Context, sql, args).Scan(&model.Id)
+	if err != nil {
+		return Notification{}, err
+	}
+
+	return model, nil
+}
+
+// Mark notifications as sent.
+func (r *PostgresNotificationRepository) MarkSent(ids []int) error {
+	sql := "UPDATE notifications SET status = @sent, sent_at = @sent_at, error = '' WHERE id = ANY(@ids)"
+
+	args := pgx.NamedArgs{
+		"ids":     ids,
+		"sent":    NotificationStatusSent,
+		"sent_at": helpers.TimeToDatabase(time.Now()),
+	}
+
+	_, err := r.db.Connection.Exec(r.db.Context, sql, args)
+
+	return err
+}
+
+// Save result of failed delivery attempt: when to try again, or dead status if there will be no more attempts.
+func (r *PostgresNotificationRepository) MarkFailed(model Notification) error {
+	sql := "UPDATE notifications SET status = @status, attempts = @attempts, next_attempt_at = @next_attempt_at, error = @error" +
+		" WHERE id = @id"
+
+	args := pgx.NamedArgs{
+		"id":              model.Id,
+		"status":          model.Status,
+		"attempts":        model.Attempts,
+		"next_attempt_at": helpers.TimeToDatabase(model.NextAttemptAt),
+		"error":           model.Error,
+	}
+
+	_, err := r.db.Connection.Exec(r.db.Context, sql, args)
+
+	return err
+}
+
+// Execute SQL and fetch multiple models.
+func (r *PostgresNotificationRepository) fetchModels(sql string, args pgx.NamedArgs) []Notification {
+	rows, err := r.db.Connection.Query(r.db.Context, sql, args)
+	if err != nil {
+		r.logger.Println("Unable to execute query:", err)
+		return nil
+	}
+
+	models, err := pgx.CollectRows[Notification](rows, r.rowToModel)
+	if err != nil {
+		r.logger.Println("Unable to collect rows:", err)
+		return nil
+	}
+
+	return models
+}
+
+// Scan data from row to model.
+func (r *PostgresNotificationRepository) rowToModel(row pgx.CollectableRow) (Notification, error) {
+	model := Notification{}
+
+	err := row.Scan(
+		&model.Id,
+		&model.TelegramChatId,
+		&model.TelegramUserId,
+		&model.ProductId,
+		&model.Url,
+		&model.Marketplace,
+		&model.Title,
+		&model.OldPrice,
+		&model.NewPrice,
+		&model.TargetPrice,
+		&model.OutOfStock,
+		&model.Alerts,
+		&model.Event,
+		&model.CreatedAt,
+		&model.SentAt,
+		&model.Status,
+		&model.Attempts,
+		&model.NextAttemptAt,
+		&model.Error,
+		&model.TelegramUserName,
+	)
+
+	return model, err
+}
@@ -0,0 +1,193 @@
+package notification
+
+import (
+	"bot/internal/app/database"
+	"bot/internal/app/helpers"
+	"bot/internal/app/logger"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+type PostgresSettingsRepository struct {
+	db     *database.Postgres
+	logger logger.LoggerInterface
+}
+
+func NewPostgresSettingsRepository(db *database.Postgres, logger logger.LoggerInterface) PostgresSettingsRepository {
+	return PostgresSettingsRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// Find settings of user, empty model is returned if user hasn't changed any settings.
+func (r *PostgresSettingsRepository) FindForUser(telegramChatId int, telegramUserId int) (UserSettings, error) {
+	sql := "SELECT * FROM user_settings WHERE telegram_chat_id = @telegram_chat_id AND telegram_user_id = @telegram_user_id"
+
+	args := pgx.NamedArgs{
+		"telegram_chat_id": telegramChatId,
+		"telegram_user_id": telegramUserId,
+	}
+
+	rows, err := r.db.Connection.Query(r.db.Context, sql, args)
+	if err != nil {
+		return UserSettings{}, err
+	}
+
+	model, err := pgx.CollectExactlyOneRow(rows, r.rowToModel)
+	if err == pgx.ErrNoRows {
+		return UserSettings{}, nil
+	}
+
+	return model, err
+}
+
+// Find settings of users whose unchanged prices digest was never sent or sent at least the chosen number of days ago.
+func (r *PostgresSettingsRepository) FindUnchangedDigestDue(now time.Time) []UserSettings {
+	sql := "SELECT * FROM user_settings" +
+		" WHERE unchanged_digest_days > 0" +
+		" AND (unchanged_digest_sent_at IS NULL OR unchanged_digest_sent_at + make_interval(days => unchanged_digest_days) <= @now)"
+
+	args := pgx.NamedArgs{
+		"now": helpers.TimeToDatabase(now),
+	}
+
+	rows, err := r.db.Connection.Query(r.db.Context, sql, args)
+	if err != nil {
+		r.logger.Println("Unable to execute query:", err)
+		return nil
+	}
+
+	models, err := pgx.CollectRows[UserSettings](rows, r.rowToModel)
+	if err != nil {
+		r.logger.Println("Unable to collect rows:", err)
+		return nil
+	}
+
+	return models
+}
+
+// Find settings of users who have enabled digest of notifications.
+func (r *PostgresSettingsRepository) FindDigestEnabled() []UserSettings {
+	sql := "SELECT * FROM user_settings WHERE digest_mode <> @off"
+
+	args := pgx.NamedArgs{
+		"off": DigestModeOff,
+	}
+
+	rows, err := r.db.Connection.Query(r.db.Context, sql, args)
+	if err != nil {
+		r.logger.Println("Unable to execute query:", err)
+		return nil
+	}
+
+	models, err := pgx.CollectRows[UserSettings](rows, r.rowToModel)
+	if err != nil {
+		r.logger.Println("Unable to collect rows:", err)
+		return nil
+	}
+
+	return models
+}
+
+// Set time when unchanged prices digest is sent.
+func (r *PostgresSettingsRepository) SetUnchangedDigestSentAt(id int, sentAt time.Time) error {
+	sql := "UPDATE user_settings SET unchanged_digest_sent_at = @sent_at WHERE id = @id"
+
+	return r.updateSentAt(sql, id, sentAt)
+}
+
+// Set time when digest of notifications is sent.
+func (r *PostgresSettingsRepository) SetDigestSentAt(id int, sentAt time.Time) error {
+	sql := "UPDATE user_settings SET digest_sent_at = @sent_at WHERE id = @id"
+
+	return r.updateSentAt(sql, id, sentAt)
+}
+
+// Save settings of user (insert or update), sending times are not changed.
+func (r *PostgresSettingsRepository) Save(model UserSettings) (UserSettings, error) {
+	sql := `INSERT INTO user_settings (
+		telegram_chat_id,
+		telegram_user_id,
+		notify_price_increase,
+		notify_sold_out,
+		unchanged_digest_days,
+		digest_mode,
+		digest_hour,
+		timezone,
+		updated_at
+	) VALUES (
+		@telegram_chat_id,
+		@telegram_user_id,
+		@notify_price_increase,
+		@notify_sold_out,
+		@unchanged_digest_days,
+		@digest_mode,
+		@digest_hour,
+		@timezone,
+		@updated_at
+	) ON CONFLICT (telegram_chat_id, telegram_user_id) DO UPDATE SET
+		notify_price_increase = EXCLUDED.notify_price_increase,
+		notify_sold_out = EXCLUDED.notify_sold_out,
+		unchanged_digest_days = EXCLUDED.unchanged_digest_days,
+		digest_mode = EXCLUDED.digest_mode,
+		digest_hour = EXCLUDED.digest_hour,
+		timezone = EXCLUDED.timezone,
+		updated_at = EXCLUDED.updated_at
+	RETURNING id`
+
+	model.UpdatedAt = time.Now()
+
+	args := pgx.NamedArgs{
+		"telegram_chat_id":      model.TelegramChatId,
+		"telegram_user_id":      model.TelegramUserId,
+		"notify_price_increase": model.NotifyPriceIncrease,
+		"notify_sold_out":       model.NotifySoldOut,
+		"unchanged_digest_days": model.UnchangedDigestDays,
+		"digest_mode":           model.DigestMode,
+		"digest_hour":           model.DigestHour,
+		"timezone":              model.Timezone,
+		"updated_at":            helpers.TimeToDatabase(model.UpdatedAt),
+	}
+
+	err := r.db.Connection.QueryRow(r.db.Context, sql, args).Scan(&model.Id)
+	if err != nil {
+		return UserSettings{}, err
+	}
+
+	return model, nil
+}
+
+func (r *PostgresSettingsRepository) updateSentAt(sql string, id int, sentAt time.Time) error {
+	args := pgx.NamedArgs{
+		"id":      id,
+		"sent_at": helpers.TimeToDatabase(sentAt),
+	}
+
+	_, err := r.db.Connection.Exec(r.db.Context, sql, args)
+
+	return err
+}
+
+// Scan data from row to model.
+func (r *PostgresSettingsRepository) rowToModel(row pgx.CollectableRow) (UserSettings, error) {
+	model := UserSettings{}
+
+	err := row.Scan(
+		&model.Id,
+		&model.TelegramChatId,
+		&model.TelegramUserId,
+		&model.NotifyPriceIncrease,
+		&model.NotifySoldOut,
+		&model.UnchangedDigestDays,
+		&model.UnchangedDigestSentAt,
+		&model.UpdatedAt,
+		&model.DigestMode,
+		&model.DigestHour,
+		&model.Timezone,
+		&model.DigestSentAt,
+	)
+
+	return model, err
+}
@@ -0,0 +1,213 @@
+package notification
+
+import (
+	"bot/internal/app/logger"
+	"bot/internal/app/marketplace"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+type SettingsRepository interface {
+	FindForUser(telegramChatId int, telegramUserId int) (UserSettings, error)
+	FindUnchangedDigestDue(now time.Time) []UserSettings
+	FindDigestEnabled() []UserSettings
+	SetUnchangedDigestSentAt(id int, sentAt time.Time) error
+	SetDigestSentAt(id int, sentAt time.Time) error
+	Save(model UserSettings) (UserSettings, error)
+}
+
+type NotificationRepository interface {
+	FindPendingForUser(telegramChatId int, telegramUserId int) []Notification
+	ClaimDue(now time.Time, leaseUntil time.Time, limit int) []Notification
+	FindDead(limit int) []Notification
+	GetCountByStatus() (map[NotificationStatus]int, error)
+	Create(transaction pgx.Tx, model Notification) (Notification, error)
+	MarkSent(ids []int) error
+	MarkFailed(model Notification) error
+}
+
+// Number of days options of unchanged prices digest, zero disables the digest.
+var UnchangedDigestDaysOptions = []int{0, 7, 14, 30}
+
+// Digest mode options in order of switching.
+var DigestModeOptions = []DigestMode{DigestModeOff, DigestModeDaily, DigestModeWeekly}
+
+// Hour options of digest sending (local time of user).
+var DigestHourOptions = []int{9, 12, 18, 21}
+
+type Service struct {
+	settingsRepository     SettingsRepository
+	notificationRepository NotificationRepository
+	logger                 logger.LoggerInterface
+}
+
+func NewService(settingsRepository SettingsRepository, notificationRepository NotificationRepository, logger logger.LoggerInterface) Service {
+	return Service{
+		settingsRepository:     settingsRepository,
+		notificationRepository: notificationRepository,
+		logger:                 logger,
+	}
+}
+
+// Get settings of user (the default ones, if user hasn't changed them).
+func (s *Service) GetSettings(telegramChatId int, telegramUserId int) UserSettings {
+	settings, err := s.settingsRepository.FindForUser(telegramChatId, telegramUserId)
+	if err != nil {
+		s.logger.Println("Unable to find user settings:", err)
+	}
+
+	if !settings.Exists() {
+		settings.DigestHour = DigestHourOptions[0]
+	}
+
+	settings.TelegramChatId = telegramChatId
+	settings.TelegramUserId = telegramUserId
+
+	return settings
+}
+
+func (s *Service) SaveSettings(settings UserSettings) (UserSettings, error) {
+	settings, err := s.settingsRepository.Save(settings)
+	if err != nil {
+		s.logger.Println("Unable to save user settings:", err)
+		return UserSettings{}, err
+	}
+
+	return settings, nil
+}
+
+// Find settings of users who are waiting for unchanged prices digest (it's sent once per the chosen number of days).
+func (s *Service) FindUnchangedDigestDue() []UserSettings {
+	return s.settingsRepository.FindUnchangedDigestDue(time.Now())
+}
+
+// Remember that unchanged prices digest is sent to user.
+func (s *Service) MarkUnchangedDigestSent(settings UserSettings) error {
+	return s.settingsRepository.SetUnchangedDigestSentAt(settings.Id, time.Now())
+}
+
+// Find settings of users whose digest of notifications has to be sent now.
+func (s *Service) FindDigestsDue(defaultLocation *time.Location) []UserSettings {
+	var due []UserSettings
+
+	now := time.Now()
+
+	for _, settings := range s.settingsRepository.FindDigestEnabled() {
+		if IsDigestDue(settings, now, defaultLocation) {
+			due = append(due, settings)
+		}
+	}
+
+	return due
+}
+
+// Remember that digest of notifications is sent to user.
+func (s *Service) MarkDigestSent(settings UserSettings) error {
+	return s.settingsRepository.SetDigestSentAt(settings.Id, time.Now())
+}
+
+// Write notification of watcher result to outbox within transaction of product update, so it's not lost.
+// Result is skipped if there are no fired rules and no events which user has opted in for.
+func (s *Service) AddWatcherResult(transaction pgx.Tx, result marketplace.WatcherResult) error {
+	event := EventType("")
+
+	if len(result.Alerts) == 0 {
+		settings := s.GetSettings(result.Original.GetTelegramChatId(), result.Original.GetTelegramUserId())
+		events := DetectOptInEvents(settings, result.Original, result.Scraped)
+
+		if len(events) == 0 {
+			return nil
+		}
+
+		event = events[0]
+	}
+
+	_, err := s.notificationRepository.Create(transaction, NewNotification(result, event))
+	if err != nil {
+		s.logger.Println("Unable to create notification:", err)
+	}
+
+	return err
+}
+
+// Claim notifications which have to be sent now (instantly, not in digest).
+func (s *Service) ClaimDue(limit int) []Notification {
+	now := time.Now()
+
+	return s.notificationRepository.ClaimDue(now, now.Add(deliveryLease), limit)
+}
+
+// Remember failed delivery attempt, notification is dead after too many attempts (or if it's undeliverable).
+func (s *Service) MarkFailed(notification Notification, deliveryErr error) error {
+	notification.Attempts++
+	notification.Error = deliveryErr.Error()
+	notification.NextAttemptAt = time.Now().Add(GetRetryDelay(notification.Attempts))
+
+	if notification.Attempts >= DeliveryMaxAttempts || errors.Is(deliveryErr, ErrUndeliverable) {
+		notification.Status = NotificationStatusDead
+	}
+
+	return s.notificationRepository.MarkFailed(notification)
+}
+
+func (s *Service) FindDead(limit int) []Notification {
+	return s.notificationRepository.FindDead(limit)
+}
+
+func (s *Service) GetCountByStatus() (map[NotificationStatus]int, error) {
+	return s.notificationRepository.GetCountByStatus()
+}
+
+// Find notifications which are waiting for digest of user.
+func (s *Service) FindPendingForUser(telegramChatId int, telegramUserId int) []Notification {
+	return s.notificationRepository.FindPendingForUser(telegramChatId, telegramUserId)
+}
+
+func (s *Service) MarkSent(notifications []Notification) error {
+	if len(notifications) == 0 {
+		return nil
+	}
+
+	ids := make([]int, 0, len(notifications))
+
+	for _, notification := range notifications {
+		ids = append(ids, notification.Id)
+	}
+
+	return s.notificationRepository.MarkSent(ids)
+}
+
+// Get the next option of unchanged prices digest days (e.g. to switch it by button).
+func GetNextUnchangedDigestDays(days int) int {
+	for i, option := range UnchangedDigestDaysOptions {
+		if option == days {
+			return UnchangedDigestDaysOptions[(i+1)%len(UnchangedDigestDaysOptions)]
+		}
+	}
+
+	return UnchangedDigestDaysOptions[0]
+}
+
+// Get the next digest mode (e.g. to switch it by button).
+func GetNextDigestMode(mode DigestMode) DigestMode {
+	for i, option := range DigestModeOptions {
+		if option == mode {
+			return DigestModeOptions[(i+1)%len(DigestModeOptions)]
+		}
+	}
+
+	return DigestModeOptions[0]
+}
+
+// Get the next hour option of digest sending (e.g. to switch it by button).
+func GetNextDigestHour(hour int) int {
+	for i, option := range DigestHourOptions {
+		if option == hour {
+			return DigestHourOptions[(i+1)%len(DigestHourOptions)]
+		}
+	}
+
+	return DigestHourOptions[0]
+}
@@ -7,6 +7,7 @@ import (
 
 var ErrUnknownEvent error = errors.New("unknown event")
 var ErrUnsupportedTransition error = errors.New("unsupported transition from current state")
+var ErrUnknownState error = errors.New("unknown state")
 
 type State string
 type Event string
@@ -96,3 +97,23 @@ func (sm *StateMachine) Reset() {
 func (sm *StateMachine) IsInitialized() bool {
 	return sm.mutex != nil
 }
+
+// Restore previously saved state (e.g. after restart), state must be one of transitions' target states.
+func (sm *StateMachine) Restore(state State) error {
+	sm.mutex.Lock()
+	defer sm.mutex.Unlock()
+
+	if state == sm.initState || state == StateIdle {
+		sm.currState = state
+		return nil
+	}
+
+	for _, transition := range sm.Transitions {
+		if transition.To == state {
+			sm.currState = state
+			return nil
+		}
+	}
+
+	return ErrUnknownState
+}
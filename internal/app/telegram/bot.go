@@ -4,11 +4,11 @@ import (
 	"bot/internal/app/helpers"
 	"bot/internal/app/logger"
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"io"
 	"net/http"
-	"strconv"
 	"strings"
 	"time"
 )
@@ -25,6 +25,16 @@ const (
 	EmojiWhiteFrowningFace Emoji = "☹️"
 	EmojiWhiteCheckMark    Emoji = "✅"
 	EmojiX                 Emoji = "❌"
+	EmojiPauseButton       Emoji = "⏸️"
+	EmojiPlayButton        Emoji = "▶️"
+	EmojiLink              Emoji = "🔗"
+	EmojiChartIncreasing   Emoji = "📈"
+	EmojiPencil            Emoji = "✏️"
+	EmojiWastebasket       Emoji = "🗑"
+	EmojiBell              Emoji = "🔔"
+	EmojiChartDecreasing   Emoji = "📉"
+	EmojiHourglass         Emoji = "⏳"
+	EmojiGear              Emoji = "⚙️"
 )
 
 type UrlParams interface {
@@ -35,18 +45,35 @@ type RequestData interface {
 	ToJson() ([]byte, error)
 }
 
+type MultipartRequestData interface {
+	ToMultipart() (*bytes.Buffer, string, error)
+}
+
 type Response struct {
-	Ok          bool            `json:"ok"`
-	Result      json.RawMessage `json:"result,omitempty"`
-	ErrorCode   int             `json:"error_code,omitempty"`
-	Description string          `json:"description,omitempty"`
+	Ok          bool               `json:"ok"`
+	Result      json.RawMessage    `json:"result,omitempty"`
+	ErrorCode   int                `json:"error_code,omitempty"`
+	Description string             `json:"description,omitempty"`
+	Parameters  ResponseParameters `json:"parameters,omitempty"`
+}
+
+// https://core.telegram.org/bots/api#responseparameters
+type ResponseParameters struct {
+	RetryAfter int `json:"retry_after,omitempty"`
 }
 
+const requestMaxAttempts = 5
+
+// Delay before the first retry of failed request, it's doubled on every next attempt.
+var requestRetryDelay = time.Second
+
 type Bot struct {
 	apiEndpoint string
 	token       string
 	WhoAmI      BotUser
 	logger      logger.LoggerInterface
+	limiter     *RateLimiter
+	noRetries   bool
 }
 
 // Constructor.
@@ -55,6 +82,7 @@ func NewBot(token string, logger logger.LoggerInterface) (Bot, error) {
 		apiEndpoint: "https://api.telegram.org/bot<token>/<method>",
 		token:       token,
 		logger:      logger,
+		limiter:     NewRateLimiter(globalRequestsPerSecond, chatRequestsPerSecond, chatRequestsBurst, groupRequestsPerSecond, groupRequestsBurst),
 	}
 
 	whoAmI, err := bot.getMe()
@@ -68,6 +96,13 @@ func NewBot(token string, logger logger.LoggerInterface) (Bot, error) {
 	return bot, nil
 }
 
+// Copy of the bot, which doesn't repeat failed requests (e.g. caller retries them on its own).
+func (b Bot) WithoutRetries() Bot {
+	b.noRetries = true
+
+	return b
+}
+
 // Get basic information about the bot.
 // https://core.telegram.org/bots/api#getme
 func (b *Bot) getMe() (BotUser, error) {
@@ -88,7 +123,7 @@ func (b *Bot) getMe() (BotUser, error) {
 
 // Get incoming updates.
 // https://core.telegram.org/bots/api#getupdates
-func (b *Bot) getUpdates(offset int) ([]Update, error) {
+func (b *Bot) getUpdates(ctx context.Context, offset int) ([]Update, error) {
 	var result []Update
 
 	endpoint := b.getEndpoint("getUpdates", &GetUpdatesParams{
@@ -96,7 +131,7 @@ func (b *Bot) getUpdates(offset int) ([]Update, error) {
 		Timeout: 20,
 	})
 
-	response, err := b.sendRequest(endpoint, nil, true)
+	response, err := b.sendRequestWithContext(ctx, endpoint, nil, true)
 
 	if err != nil {
 		return result, err
@@ -109,35 +144,37 @@ func (b *Bot) getUpdates(offset int) ([]Update, error) {
 	return result, nil
 }
 
-// Listen for incoming updates and apply a callback function to each item.
-func (b *Bot) ListenForUpdates(callback func(update Update), updateIdOffset int) {
-	updatesChannel := make(chan Update)
+// Listen for incoming updates and apply a callback function to each item until context is cancelled.
+// Offset is moved (so Telegram forgets the update) only after callback is done with the update.
+func (b *Bot) ListenForUpdates(ctx context.Context, callback func(update Update), updateIdOffset int) {
+	for ctx.Err() == nil {
+		updates, err := b.getUpdates(ctx, updateIdOffset)
 
-	go func() {
-		for {
-			updates, err := b.getUpdates(updateIdOffset)
+		if err != nil && ctx.Err() == nil {
+			b.logger.Println("Failed to get updates, retrying in 10 seconds...", err)
+
+			select {
+			case <-ctx.Done():
+			case <-time.After(time.Second * 10):
+			}
 
-			if err != nil {
-				b.logger.Println("Failed to get updates, retrying in 3 seconds...", err)
+			continue
+		}
 
-				time.Sleep(time.Second * 10)
+		for _, update := range updates {
+			// the rest of updates is not acknowledged, so Telegram sends them again after restart
+			if ctx.Err() != nil {
+				return
+			}
 
+			if update.UpdateId < updateIdOffset {
 				continue
 			}
 
-			for _, update := range updates {
-				if update.UpdateId < updateIdOffset {
-					continue
-				}
+			callback(update)
 
-				updateIdOffset = update.UpdateId + 1
-				updatesChannel <- update
-			}
+			updateIdOffset = update.UpdateId + 1
 		}
-	}()
-
-	for update := range updatesChannel {
-		callback(update)
 	}
 }
 
@@ -146,6 +183,8 @@ func (b *Bot) ListenForUpdates(callback func(update Update), updateIdOffset int)
 func (b *Bot) SendMessage(toChatId int, request SendMessageRequest) (Message, error) {
 	var result Message
 
+	b.waitForChat(toChatId)
+
 	endpoint := b.getEndpoint("sendMessage", &SendMessageParams{
 		ChatId: toChatId,
 	})
@@ -163,9 +202,59 @@ func (b *Bot) SendMessage(toChatId int, request SendMessageRequest) (Message, er
 	return result, nil
 }
 
+// Send photo as multipart upload.
+// https://core.telegram.org/bots/api#sendphoto
+func (b *Bot) SendPhoto(toChatId int, request SendPhotoRequest) (Message, error) {
+	var result Message
+
+	b.waitForChat(toChatId)
+
+	endpoint := b.getEndpoint("sendPhoto", &SendPhotoParams{
+		ChatId: toChatId,
+	})
+
+	response, err := b.sendMultipartRequest(endpoint, &request)
+
+	if err != nil {
+		return result, err
+	}
+
+	if err := json.Unmarshal(response.Result, &result); err != nil {
+		return result, err
+	}
+
+	return result, nil
+}
+
+// Get information about member of chat (e.g. to check if user is administrator).
+// https://core.telegram.org/bots/api#getchatmember
+func (b *Bot) GetChatMember(chatId int, userId int) (ChatMember, error) {
+	var result ChatMember
+
+	b.waitGlobal()
+
+	endpoint := b.getEndpoint("getChatMember", &GetChatMemberParams{
+		ChatId: chatId,
+		UserId: userId,
+	})
+
+	response, err := b.sendRequest(endpoint, nil, false)
+	if err != nil {
+		return result, err
+	}
+
+	if err := json.Unmarshal(response.Result, &result); err != nil {
+		return result, err
+	}
+
+	return result, nil
+}
+
 // Answer to callback query.
 // https://core.telegram.org/bots/api#answercallbackquery
 func (b *Bot) AnswerCallbackQuery(callbackQueryId string) {
+	b.waitGlobal()
+
 	endpoint := b.getEndpoint("answerCallbackQuery", &AnswerCallbackQueryParams{
 		CallbackQueryId: callbackQueryId,
 	})
@@ -176,6 +265,9 @@ func (b *Bot) AnswerCallbackQuery(callbackQueryId string) {
 // Edit message.
 // https://core.telegram.org/bots/api#editmessagetext
 func (b *Bot) EditMessage(chatId int, messageId int, request EditMessageRequest) {
+	// per chat limit is for new messages, edits (e.g. progress of scraping) are limited only globally
+	b.waitGlobal()
+
 	endpoint := b.getEndpoint("editMessageText", &EditMessageParams{
 		ChatId:    chatId,
 		MessageId: messageId,
@@ -185,6 +277,8 @@ func (b *Bot) EditMessage(chatId int, messageId int, request EditMessageRequest)
 }
 
 func (b *Bot) SetMessageReaction(toChatId int, toMessageId int, emoji Emoji) {
+	b.waitForChat(toChatId)
+
 	endpoint := b.getEndpoint("setMessageReaction", &SetMessageReactionParams{
 		ChatId:    toChatId,
 		MessageId: toMessageId,
@@ -197,6 +291,20 @@ func (b *Bot) SetMessageReaction(toChatId int, toMessageId int, emoji Emoji) {
 	b.sendRequest(endpoint, &request, false)
 }
 
+// Wait until rate limits allow to send message to chat.
+func (b *Bot) waitForChat(chatId int) {
+	if b.limiter != nil {
+		b.limiter.Wait(chatId)
+	}
+}
+
+// Wait until global rate limit allows to send request, which is not a new message of chat.
+func (b *Bot) waitGlobal() {
+	if b.limiter != nil {
+		b.limiter.WaitGlobal()
+	}
+}
+
 // Get method endpoint with optional URL parameters.
 func (b *Bot) getEndpoint(method string, params UrlParams) string {
 	endpoint := strings.Replace(b.apiEndpoint, "<method>", method, 1)
@@ -210,6 +318,11 @@ func (b *Bot) getEndpoint(method string, params UrlParams) string {
 
 // Send request to endpoint with optional data.
 func (b *Bot) sendRequest(endpoint string, data RequestData, skipLogMessage bool) (Response, error) {
+	return b.sendRequestWithContext(context.Background(), endpoint, data, skipLogMessage)
+}
+
+// Send request to endpoint with optional data, request is aborted when context is cancelled.
+func (b *Bot) sendRequestWithContext(ctx context.Context, endpoint string, data RequestData, skipLogMessage bool) (Response, error) {
 	var httpMethod string
 
 	body := bytes.NewBuffer(nil)
@@ -236,16 +349,63 @@ func (b *Bot) sendRequest(endpoint string, data RequestData, skipLogMessage bool
 		}
 	}
 
+	return b.doRequest(ctx, httpMethod, endpoint, body, "application/json")
+}
+
+// Send multipart request (e.g. with file upload) to endpoint.
+func (b *Bot) sendMultipartRequest(endpoint string, data MultipartRequestData) (Response, error) {
+	body, contentType, err := data.ToMultipart()
+	if err != nil {
+		b.logger.Println(err)
+		return Response{}, err
+	}
+
+	b.logger.Println("Sending multipart POST request to", endpoint, "with", body.Len(), "byte(s) of data")
+
+	return b.doRequest(context.Background(), http.MethodPost, endpoint, body, contentType)
+}
+
+// Perform HTTP request and decode response, request is repeated if Telegram asks to retry later or fails.
+func (b *Bot) doRequest(ctx context.Context, httpMethod string, endpoint string, body *bytes.Buffer, contentType string) (Response, error) {
+	retryDelay := requestRetryDelay
+
+	for attempt := 1; ; attempt++ {
+		response, err := b.doRequestAttempt(ctx, httpMethod, endpoint, body.Bytes(), contentType)
+
+		var apiError *ApiError
+		if !errors.As(err, &apiError) || !apiError.IsRetryable() || attempt >= requestMaxAttempts || b.noRetries {
+			return response, err
+		}
+
+		delay := retryDelay
+		if apiError.RetryAfter > 0 {
+			delay = time.Duration(apiError.RetryAfter) * time.Second
+		} else {
+			retryDelay *= 2
+		}
+
+		b.logger.Println("Request failed with", err, "retrying in", delay)
+
+		select {
+		case <-ctx.Done():
+			return response, err
+		case <-time.After(delay):
+		}
+	}
+}
+
+// Perform single HTTP request.
+func (b *Bot) doRequestAttempt(ctx context.Context, httpMethod string, endpoint string, body []byte, contentType string) (Response, error) {
 	// don't expose token in logs
 	endpoint = strings.Replace(endpoint, "<token>", b.token, 1)
 
-	request, err := http.NewRequest(httpMethod, endpoint, body)
+	request, err := http.NewRequestWithContext(ctx, httpMethod, endpoint, bytes.NewReader(body))
 	if err != nil {
 		b.logger.Println(err)
 		return Response{}, err
 	}
 
-	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("Content-Type", contentType)
 
 	client := http.Client{}
 
@@ -257,7 +417,17 @@ func (b *Bot) sendRequest(endpoint string, data RequestData, skipLogMessage bool
 
 	defer response.Body.Close()
 
-	return b.decodeResponse(response.Body)
+	decoded, err := b.decodeResponse(response.Body)
+
+	// e.g. HTML page of proxy instead of JSON
+	if err != nil && response.StatusCode >= http.StatusInternalServerError {
+		return Response{}, &ApiError{
+			Code:        response.StatusCode,
+			Description: http.StatusText(response.StatusCode),
+		}
+	}
+
+	return decoded, err
 }
 
 // Decode response to generic struct.
@@ -273,8 +443,11 @@ func (b *Bot) decodeResponse(data io.Reader) (Response, error) {
 	}
 
 	if !responseDecoded.Ok {
-		errorMessage := helpers.ConcatStrings(strconv.Itoa(responseDecoded.ErrorCode), ": ", responseDecoded.Description)
-		return Response{}, errors.New(errorMessage)
+		return Response{}, &ApiError{
+			Code:        responseDecoded.ErrorCode,
+			Description: responseDecoded.Description,
+			RetryAfter:  responseDecoded.Parameters.RetryAfter,
+		}
 	}
 
 	return responseDecoded, nil
@@ -0,0 +1,129 @@
+package telegram
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+type testLogger struct{}
+
+func (l testLogger) Println(message ...any) {}
+
+// Bot which sends requests to test server, server responds with the given responses one by one.
+func newTestBot(t *testing.T, responses ...string) (*Bot, *int) {
+	requestsCount := 0
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		response := responses[min(requestsCount, len(responses)-1)]
+		requestsCount++
+
+		if response == "" {
+			http.Error(w, "Bad Gateway", http.StatusBadGateway)
+			return
+		}
+
+		fmt.Fprint(w, response)
+	}))
+
+	t.Cleanup(server.Close)
+
+	defaultRetryDelay := requestRetryDelay
+	requestRetryDelay = time.Millisecond
+
+	t.Cleanup(func() {
+		requestRetryDelay = defaultRetryDelay
+	})
+
+	bot := &Bot{
+		apiEndpoint: server.URL + "/bot<token>/<method>",
+		token:       "token",
+		logger:      testLogger{},
+	}
+
+	return bot, &requestsCount
+}
+
+func TestBotRetriesServerErrors(t *testing.T) {
+	bot, requestsCount := newTestBot(t, "", `{"ok": false, "error_code": 500, "description": "Internal Server Error"}`, `{"ok": true, "result": {"message_id": 7}}`)
+
+	message, err := bot.SendMessage(42, SendMessageRequest{Text: "Hello"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v.", err)
+	}
+
+	if message.MessageId != 7 {
+		t.Errorf("Invalid message id, got: %d, instead of: %d.", message.MessageId, 7)
+	}
+
+	if *requestsCount != 3 {
+		t.Errorf("Invalid requests count, got: %d, instead of: %d.", *requestsCount, 3)
+	}
+}
+
+func TestBotRetriesTooManyRequests(t *testing.T) {
+	bot, requestsCount := newTestBot(t, `{"ok": false, "error_code": 429, "description": "Too Many Requests: retry after 1", "parameters": {"retry_after": 1}}`, `{"ok": true, "result": {"message_id": 7}}`)
+
+	start := time.Now()
+
+	_, err := bot.SendMessage(42, SendMessageRequest{Text: "Hello"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v.", err)
+	}
+
+	if elapsed := time.Since(start); elapsed < time.Second {
+		t.Errorf("Request is retried too early, after: %v.", elapsed)
+	}
+
+	if *requestsCount != 2 {
+		t.Errorf("Invalid requests count, got: %d, instead of: %d.", *requestsCount, 2)
+	}
+}
+
+func TestBotGivesUpAfterMaxAttempts(t *testing.T) {
+	bot, requestsCount := newTestBot(t, "")
+
+	_, err := bot.SendMessage(42, SendMessageRequest{Text: "Hello"})
+	if !errors.Is(err, ErrServerError) {
+		t.Errorf("Invalid error, got: %v, instead of: %v.", err, ErrServerError)
+	}
+
+	if *requestsCount != requestMaxAttempts {
+		t.Errorf("Invalid requests count, got: %d, instead of: %d.", *requestsCount, requestMaxAttempts)
+	}
+}
+
+func TestBotWithoutRetries(t *testing.T) {
+	bot, requestsCount := newTestBot(t, "")
+	withoutRetries := bot.WithoutRetries()
+
+	_, err := withoutRetries.SendMessage(42, SendMessageRequest{Text: "Hello"})
+	if !errors.Is(err, ErrServerError) {
+		t.Errorf("Invalid error, got: %v, instead of: %v.", err, ErrServerError)
+	}
+
+	if *requestsCount != 1 {
+		t.Errorf("Invalid requests count, got: %d, instead of: %d.", *requestsCount, 1)
+	}
+}
+
+func TestBotDoesNotRetryClientErrors(t *testing.T) {
+	bot, requestsCount := newTestBot(t, `{"ok": false, "error_code": 403, "description": "Forbidden: bot was blocked by the user"}`)
+
+	_, err := bot.SendMessage(42, SendMessageRequest{Text: "Hello"})
+	if !errors.Is(err, ErrBotBlocked) {
+		t.Errorf("Invalid error, got: %v, instead of: %v.", err, ErrBotBlocked)
+	}
+
+	var apiError *ApiError
+	if !errors.As(err, &apiError) || apiError.Description != "Forbidden: bot was blocked by the user" {
+		t.Errorf("Invalid API error, got: %v.", err)
+	}
+
+	if *requestsCount != 1 {
+		t.Errorf("Invalid requests count, got: %d, instead of: %d.", *requestsCount, 1)
+	}
+}
@@ -0,0 +1,67 @@
+package telegram
+
+import (
+	"bot/internal/app/helpers"
+	"errors"
+	"strconv"
+	"strings"
+)
+
+// Telegram doesn't accept callback data longer than 64 bytes.
+const callbackDataMaxLength = 64
+
+const callbackDataSeparator = ":"
+
+type CallbackAction string
+
+const (
+	CallbackActionDelete        CallbackAction = "d"
+	CallbackActionConfirmDelete CallbackAction = "y"
+	CallbackActionCancelDelete  CallbackAction = "n"
+	CallbackActionHistory       CallbackAction = "h"
+	CallbackActionSetPrice      CallbackAction = "p"
+	CallbackActionPause         CallbackAction = "s"
+	CallbackActionResume        CallbackAction = "r"
+	CallbackActionAlertRules    CallbackAction = "a"
+)
+
+var (
+	ErrInvalidCallbackData = errors.New("invalid callback data")
+	ErrCallbackDataTooLong = errors.New("callback data is too long")
+)
+
+// Compact action data of inline button, e.g. "d:abCdEF1:2" means "delete product abCdEF1 from the list page 2".
+type CallbackData struct {
+	Action CallbackAction
+	Slug   string
+	Page   int
+}
+
+func (d CallbackData) ToString() (string, error) {
+	data := helpers.ConcatStrings(string(d.Action), callbackDataSeparator, d.Slug, callbackDataSeparator, strconv.Itoa(d.Page))
+
+	if len(data) > callbackDataMaxLength {
+		return "", ErrCallbackDataTooLong
+	}
+
+	return data, nil
+}
+
+// Parse callback data of inline button, commands (e.g. "/page_2") are not callback data.
+func ParseCallbackData(data string) (CallbackData, error) {
+	parts := strings.Split(data, callbackDataSeparator)
+	if len(parts) != 3 || parts[0] == "" {
+		return CallbackData{}, ErrInvalidCallbackData
+	}
+
+	page, err := strconv.Atoi(parts[2])
+	if err != nil || page < 1 {
+		return CallbackData{}, ErrInvalidCallbackData
+	}
+
+	return CallbackData{
+		Action: CallbackAction(parts[0]),
+		Slug:   parts[1],
+		Page:   page,
+	}, nil
+}
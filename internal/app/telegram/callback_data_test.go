@@ -0,0 +1,53 @@
+package telegram_test
+
+import (
+	"bot/internal/app/telegram"
+	"strings"
+	"testing"
+)
+
+func TestCallbackDataRoundTrip(t *testing.T) {
+	data := telegram.CallbackData{
+		Action: telegram.CallbackActionDelete,
+		Slug:   "abCdEF1",
+		Page:   12,
+	}
+
+	encoded, err := data.ToString()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v.", err)
+	}
+
+	if encoded != "d:abCdEF1:12" {
+		t.Errorf("Invalid callback data, got: %s, instead of: %s.", encoded, "d:abCdEF1:12")
+	}
+
+	decoded, err := telegram.ParseCallbackData(encoded)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v.", err)
+	}
+
+	if decoded != data {
+		t.Errorf("Invalid decoded callback data, got: %+v, instead of: %+v.", decoded, data)
+	}
+}
+
+func TestCallbackDataTooLong(t *testing.T) {
+	data := telegram.CallbackData{
+		Action: telegram.CallbackActionDelete,
+		Slug:   strings.Repeat("a", 64),
+		Page:   1,
+	}
+
+	if _, err := data.ToString(); err != telegram.ErrCallbackDataTooLong {
+		t.Errorf("Invalid error, got: %v, instead of: %v.", err, telegram.ErrCallbackDataTooLong)
+	}
+}
+
+func TestParseInvalidCallbackData(t *testing.T) {
+	for _, data := range []string{"", "/page_2", "/yes", "d:abCdEF1", "d:abCdEF1:x", "d:abCdEF1:0", ":abCdEF1:1"} {
+		if _, err := telegram.ParseCallbackData(data); err != telegram.ErrInvalidCallbackData {
+			t.Errorf("Invalid error for \"%s\", got: %v, instead of: %v.", data, err, telegram.ErrInvalidCallbackData)
+		}
+	}
+}
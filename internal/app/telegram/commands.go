@@ -1,6 +1,9 @@
 package telegram
 
-import "strings"
+import (
+	"bot/internal/app/helpers"
+	"strings"
+)
 
 const (
 	CommandTrackProduct = "/trackproduct"
@@ -9,9 +12,21 @@ const (
 	CommandHelp         = "/help"
 	CommandYes          = "/yes"
 	CommandNo           = "/no"
+	CommandSkip         = "/skip"
+	CommandPauseAll     = "/pauseall"
+	CommandResumeAll    = "/resumeall"
+	CommandSettings     = "/settings"
+	CommandTimezone     = "/timezone"
+	CommandSharedList   = "/sharedlist"
 
 	CommandPrefixPage          = "/page_"
 	CommandPrefixDeleteProduct = "/del_"
+	CommandPrefixSetPrice      = "/price_"
+	CommandPrefixPriceHistory  = "/history_"
+	CommandPrefixPauseProduct  = "/pause_"
+	CommandPrefixResumeProduct = "/resume_"
+	CommandPrefixAlertRules    = "/rules_"
+	CommandPrefixToggleSetting = "/settings_"
 )
 
 type CommandsDictionary interface {
@@ -42,6 +57,82 @@ func IsDeleteProductCommand(command string) bool {
 	return strings.HasPrefix(command, CommandPrefixDeleteProduct)
 }
 
+func IsSetPriceCommand(command string) bool {
+	return strings.HasPrefix(command, CommandPrefixSetPrice)
+}
+
+func IsPriceHistoryCommand(command string) bool {
+	return strings.HasPrefix(command, CommandPrefixPriceHistory)
+}
+
+func IsPauseProductCommand(command string) bool {
+	return strings.HasPrefix(command, CommandPrefixPauseProduct)
+}
+
+func IsResumeProductCommand(command string) bool {
+	return strings.HasPrefix(command, CommandPrefixResumeProduct)
+}
+
+func IsAlertRulesCommand(command string) bool {
+	return strings.HasPrefix(command, CommandPrefixAlertRules)
+}
+
+func IsPauseAllCommand(command string) bool {
+	return command == CommandPauseAll
+}
+
+func IsResumeAllCommand(command string) bool {
+	return command == CommandResumeAll
+}
+
+func IsSettingsCommand(command string) bool {
+	return command == CommandSettings
+}
+
+func IsToggleSettingCommand(command string) bool {
+	return strings.HasPrefix(command, CommandPrefixToggleSetting)
+}
+
+// Timezone command goes with timezone name, e.g. "/timezone Europe/Moscow".
+func IsTimezoneCommand(command string) bool {
+	return command == CommandTimezone || strings.HasPrefix(command, CommandTimezone+" ")
+}
+
+func IsSharedListCommand(command string) bool {
+	return command == CommandSharedList
+}
+
+// Remove bot mention from message text, so commands look the same in groups as in private chats:
+// "/listproducts@bot" and "@bot /listproducts" become "/listproducts".
+// False is returned if command is addressed to another bot.
+func NormalizeCommand(text string, botUserName string) (string, bool) {
+	text = strings.TrimSpace(text)
+	mention := helpers.ConcatStrings("@", botUserName)
+
+	if botUserName != "" && len(text) >= len(mention) && strings.EqualFold(text[:len(mention)], mention) {
+		if len(text) == len(mention) || text[len(mention)] == ' ' {
+			text = strings.TrimSpace(text[len(mention):])
+		}
+	}
+
+	if !strings.HasPrefix(text, "/") {
+		return text, true
+	}
+
+	command, arguments, _ := strings.Cut(text, " ")
+
+	command, addressee, isAddressed := strings.Cut(command, "@")
+	if isAddressed && !strings.EqualFold(addressee, botUserName) {
+		return text, false
+	}
+
+	if arguments == "" {
+		return command, true
+	}
+
+	return helpers.ConcatStrings(command, " ", arguments), true
+}
+
 func isCommandInDictionary(command string, dictionary CommandsDictionary) bool {
 	command = strings.ToLower(strings.TrimSpace(command))
 
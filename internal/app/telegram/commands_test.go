@@ -0,0 +1,47 @@
+package telegram_test
+
+import (
+	"bot/internal/app/telegram"
+	"testing"
+)
+
+func TestNormalizeCommand(t *testing.T) {
+	cases := map[string]struct {
+		text string
+		isOk bool
+	}{
+		"/listproducts":                  {"/listproducts", true},
+		"/listproducts@SlodychBot":       {"/listproducts", true},
+		"/listproducts@slodychbot":       {"/listproducts", true},
+		"/del_abCdEF1@SlodychBot":        {"/del_abCdEF1", true},
+		"/timezone@SlodychBot Asia/Baku": {"/timezone Asia/Baku", true},
+		"@SlodychBot /listproducts":      {"/listproducts", true},
+		"@SlodychBot https://ozon.ru/":   {"https://ozon.ru/", true},
+		"@SlodychBotFan hello":           {"@SlodychBotFan hello", true},
+		"/listproducts@OtherBot":         {"/listproducts@OtherBot", false},
+		" hello ":                        {"hello", true},
+	}
+
+	for text, expected := range cases {
+		normalized, isOk := telegram.NormalizeCommand(text, "SlodychBot")
+
+		if normalized != expected.text || isOk != expected.isOk {
+			t.Errorf("Invalid normalized command for: %q, got: %q (%v), instead of: %q (%v).", text, normalized, isOk, expected.text, expected.isOk)
+		}
+	}
+}
+
+func TestUserGetMention(t *testing.T) {
+	users := map[string]telegram.User{
+		`<a href="tg://user?id=1">John Smith</a>`:      {Id: 1, FirstName: "John", LastName: "Smith"},
+		`<a href="tg://user?id=2">Tom &amp; Jerry</a>`: {Id: 2, FirstName: "Tom & Jerry"},
+		`<a href="tg://user?id=3">@tommy</a>`:          {Id: 3, UserName: "tommy"},
+		`<a href="tg://user?id=4">4</a>`:               {Id: 4},
+	}
+
+	for expected, user := range users {
+		if mention := user.GetMention(); mention != expected {
+			t.Errorf("Invalid mention, got: %s, instead of: %s.", mention, expected)
+		}
+	}
+}
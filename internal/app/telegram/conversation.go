@@ -2,16 +2,37 @@ package telegram
 
 import (
 	"bot/internal/app/statemachine"
-	"context"
+	"time"
 )
 
-type ConversationContextKey string
+// Draft of product which is being added to tracking.
+type ProductDraft struct {
+	ScrapedAt      time.Time `json:"scraped_at"`
+	TelegramChatId int       `json:"telegram_chat_id"`
+	TelegramUserId int       `json:"telegram_user_id"`
+	Marketplace    int       `json:"marketplace"`
+	Url            string    `json:"url"`
+	Title          string    `json:"title"`
+	ThresholdPrice int       `json:"threshold_price"`
+	CurrentPrice   int       `json:"current_price"`
+	OutOfStock     bool      `json:"out_of_stock"`
+	TargetPrice    int       `json:"target_price"`
+
+	// Name of user who adds the product, it's mentioned in notifications of group.
+	TelegramUserName string `json:"telegram_user_name"`
+}
 
-const (
-	ConversationCtxProduct     ConversationContextKey = "trackedProduct"
-	ConversationCtxMessage     ConversationContextKey = "message"
-	ConversationCtxProductSlug ConversationContextKey = "productSlug"
-)
+// Data collected during conversation, it's stored along with state of conversation.
+type ConversationData struct {
+	// Product which is being added to tracking.
+	Product *ProductDraft `json:"product,omitempty"`
+
+	// Message which is edited on page navigation (e.g. product listing).
+	Message *Message `json:"message,omitempty"`
+
+	// Product which is being deleted or edited.
+	ProductSlug string `json:"product_slug,omitempty"`
+}
 
 type Conversation struct {
 	ChatId              int
@@ -19,39 +40,20 @@ type Conversation struct {
 	LastMessage         Message
 	LastCallbackQueryId string
 	StateMachine        statemachine.StateMachine
-	ctx                 context.Context
+	Data                ConversationData
+	UpdatedAt           time.Time
 }
 
 func NewConversation(chatId int, from User) *Conversation {
 	return &Conversation{
-		ChatId: chatId,
-		User:   from,
-		ctx:    context.Background(),
-	}
-}
-
-// Get conversation context by key.
-func (c *Conversation) GetContext(key ConversationContextKey) any {
-	if c.ctx == nil {
-		return nil
+		ChatId:    chatId,
+		User:      from,
+		UpdatedAt: time.Now(),
 	}
-
-	return c.ctx.Value(key)
-}
-
-// Store conversation context by key.
-func (c *Conversation) StoreContext(key ConversationContextKey, value any) any {
-	if c.ctx == nil {
-		c.ctx = context.Background()
-	}
-
-	c.ctx = context.WithValue(c.ctx, key, value)
-
-	return c.ctx
 }
 
 // Reset conversation.
 func (c *Conversation) Reset() {
-	c.ctx = nil
+	c.Data = ConversationData{}
 	c.StateMachine.Reset()
 }
@@ -0,0 +1,63 @@
+package telegram
+
+import "sync"
+
+// Runs tasks of the same conversation one by one in order of dispatching,
+// while tasks of different conversations run in parallel.
+type ConversationManager struct {
+	queues    map[string][]func()
+	locker    sync.Mutex
+	waitGroup sync.WaitGroup
+}
+
+func NewConversationManager() *ConversationManager {
+	return &ConversationManager{
+		queues: make(map[string][]func()),
+	}
+}
+
+// Put task to the queue of conversation, worker of conversation is started if it's not running.
+func (m *ConversationManager) Dispatch(hash string, task func()) {
+	m.locker.Lock()
+	defer m.locker.Unlock()
+
+	queue, isRunning := m.queues[hash]
+
+	m.queues[hash] = append(queue, task)
+
+	if isRunning {
+		return
+	}
+
+	m.waitGroup.Add(1)
+
+	go m.work(hash)
+}
+
+// Wait for all dispatched tasks to complete.
+func (m *ConversationManager) Wait() {
+	m.waitGroup.Wait()
+}
+
+// Run tasks of conversation until its queue is empty.
+func (m *ConversationManager) work(hash string) {
+	defer m.waitGroup.Done()
+
+	for {
+		m.locker.Lock()
+
+		queue := m.queues[hash]
+		if len(queue) == 0 {
+			delete(m.queues, hash)
+			m.locker.Unlock()
+			return
+		}
+
+		task := queue[0]
+		m.queues[hash] = queue[1:]
+
+		m.locker.Unlock()
+
+		task()
+	}
+}
@@ -0,0 +1,61 @@
+package telegram_test
+
+import (
+	"bot/internal/app/telegram"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestConversationManagerKeepsOrder(t *testing.T) {
+	manager := telegram.NewConversationManager()
+
+	var locker sync.Mutex
+	var order []int
+
+	for i := 0; i < 50; i++ {
+		manager.Dispatch("abc", func() {
+			locker.Lock()
+			defer locker.Unlock()
+
+			order = append(order, i)
+		})
+	}
+
+	manager.Wait()
+
+	for i, value := range order {
+		if i != value {
+			t.Fatalf("Invalid order of tasks, got: %v.", order)
+		}
+	}
+
+	if len(order) != 50 {
+		t.Errorf("Invalid count of tasks, got: %d, instead of: %d.", len(order), 50)
+	}
+}
+
+func TestConversationManagerRunsConversationsInParallel(t *testing.T) {
+	manager := telegram.NewConversationManager()
+
+	release := make(chan struct{})
+	done := make(chan struct{})
+
+	// slow task (e.g. scraping) must not block other conversations
+	manager.Dispatch("slow", func() {
+		<-release
+	})
+
+	manager.Dispatch("fast", func() {
+		close(done)
+	})
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Error("Task of another conversation is blocked.")
+	}
+
+	close(release)
+	manager.Wait()
+}
@@ -0,0 +1,74 @@
+package telegram
+
+import (
+	"bot/internal/app/statemachine"
+	"slices"
+	"sync"
+	"time"
+)
+
+// Storage of conversations, which are identified by hash of chat and user.
+type ConversationStore interface {
+	Find(hash string) (*Conversation, bool)
+	Save(hash string, conversation *Conversation) error
+	Delete(hash string) error
+	DeleteInactive(updatedBefore time.Time, keptStates ...statemachine.State) error
+}
+
+// Conversations are lost on restart, suitable for development.
+type MemoryConversationStore struct {
+	conversations map[string]*Conversation
+	locker        sync.Mutex
+}
+
+func NewMemoryConversationStore() *MemoryConversationStore {
+	return &MemoryConversationStore{
+		conversations: make(map[string]*Conversation),
+	}
+}
+
+func (s *MemoryConversationStore) Find(hash string) (*Conversation, bool) {
+	s.locker.Lock()
+	defer s.locker.Unlock()
+
+	conversation, exists := s.conversations[hash]
+
+	return conversation, exists
+}
+
+func (s *MemoryConversationStore) Save(hash string, conversation *Conversation) error {
+	s.locker.Lock()
+	defer s.locker.Unlock()
+
+	conversation.UpdatedAt = time.Now()
+	s.conversations[hash] = conversation
+
+	return nil
+}
+
+func (s *MemoryConversationStore) Delete(hash string) error {
+	s.locker.Lock()
+	defer s.locker.Unlock()
+
+	delete(s.conversations, hash)
+
+	return nil
+}
+
+// Delete conversations which are not updated since the given time, except the ones in kept states (e.g. waiting for a job).
+func (s *MemoryConversationStore) DeleteInactive(updatedBefore time.Time, keptStates ...statemachine.State) error {
+	s.locker.Lock()
+	defer s.locker.Unlock()
+
+	for hash, conversation := range s.conversations {
+		if conversation.StateMachine.IsInitialized() && slices.Contains(keptStates, conversation.StateMachine.GetCurrentState()) {
+			continue
+		}
+
+		if conversation.UpdatedAt.Before(updatedBefore) {
+			delete(s.conversations, hash)
+		}
+	}
+
+	return nil
+}
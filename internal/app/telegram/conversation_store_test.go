@@ -0,0 +1,85 @@
+package telegram_test
+
+import (
+	"bot/internal/app/statemachine"
+	"bot/internal/app/telegram"
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestMemoryConversationStore(t *testing.T) {
+	store := telegram.NewMemoryConversationStore()
+
+	if _, exists := store.Find("abc"); exists {
+		t.Fatal("Conversation must not exist before saving.")
+	}
+
+	conversation := telegram.NewConversation(1, telegram.User{Id: 2})
+	conversation.Data.ProductSlug = "abCdEF1"
+
+	store.Save("abc", conversation)
+
+	found, exists := store.Find("abc")
+	if !exists || found.Data.ProductSlug != "abCdEF1" {
+		t.Fatalf("Invalid conversation, got: %+v.", found)
+	}
+
+	store.DeleteInactive(time.Now().Add(-time.Minute))
+
+	if _, exists := store.Find("abc"); !exists {
+		t.Fatal("Active conversation must not be deleted.")
+	}
+
+	store.DeleteInactive(time.Now().Add(time.Minute))
+
+	if _, exists := store.Find("abc"); exists {
+		t.Fatal("Inactive conversation must be deleted.")
+	}
+}
+
+func TestMemoryConversationStoreKeepsStates(t *testing.T) {
+	store := telegram.NewMemoryConversationStore()
+
+	conversation := telegram.NewConversation(1, telegram.User{Id: 2})
+	conversation.StateMachine = statemachine.NewFSM(statemachine.StateIdle, statemachine.TransitionsList{
+		"Scrape": {From: []statemachine.State{statemachine.StateIdle}, To: "Scraping"},
+	})
+	conversation.StateMachine.TriggerEvent("Scrape")
+
+	store.Save("abc", conversation)
+	store.DeleteInactive(time.Now().Add(time.Minute), "Scraping")
+
+	if _, exists := store.Find("abc"); !exists {
+		t.Fatal("Conversation in kept state must not be deleted.")
+	}
+}
+
+func TestConversationDataSerialization(t *testing.T) {
+	data := telegram.ConversationData{
+		Product: &telegram.ProductDraft{
+			TelegramChatId: 1,
+			Marketplace:    2,
+			Url:            "https://www.ozon.ru/product/some-product-123456/",
+			CurrentPrice:   129900,
+		},
+		Message: &telegram.Message{
+			MessageId: 42,
+		},
+		ProductSlug: "abCdEF1",
+	}
+
+	encoded, err := json.Marshal(data)
+	if err != nil {
+		t.Fatalf("Unable to encode data: %s.", err)
+	}
+
+	var decoded telegram.ConversationData
+	if err := json.Unmarshal(encoded, &decoded); err != nil {
+		t.Fatalf("Unable to decode data: %s.", err)
+	}
+
+	if *decoded.Product != *data.Product || decoded.Message.MessageId != 42 || decoded.ProductSlug != data.ProductSlug {
+		t.Errorf("Invalid decoded data, got: %+v, instead of: %+v.", decoded, data)
+	}
+}
@@ -0,0 +1,82 @@
+package telegram
+
+import (
+	"bot/internal/app/helpers"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+var (
+	ErrTooManyRequests = errors.New("too many requests")
+	ErrBotBlocked      = errors.New("bot is blocked by the user")
+	ErrChatNotFound    = errors.New("chat not found")
+	ErrNotEnoughRights = errors.New("not enough rights")
+	ErrServerError     = errors.New("telegram server error")
+)
+
+// Descriptions of "Forbidden" errors, after which messages can't be delivered to chat anymore
+// (other ones, e.g. missing rights, are temporary problems of chat).
+var botBlockedDescriptions = []string{
+	"bot was blocked by the user",
+	"user is deactivated",
+	"bot was kicked",
+	"bot is not a member",
+	"group chat was deleted",
+}
+
+// Error returned by Bot API.
+// https://core.telegram.org/bots/api#making-requests
+type ApiError struct {
+	Code        int
+	Description string
+
+	// Number of seconds to wait before the request can be repeated (if flood control is exceeded).
+	RetryAfter int
+}
+
+func (e *ApiError) Error() string {
+	return helpers.ConcatStrings(strconv.Itoa(e.Code), ": ", e.Description)
+}
+
+// Match error against the common ones, e.g. errors.Is(err, ErrBotBlocked).
+func (e *ApiError) Is(target error) bool {
+	switch target {
+	case ErrTooManyRequests:
+		return e.Code == http.StatusTooManyRequests
+	case ErrBotBlocked:
+		return e.Code == http.StatusForbidden && e.hasDescription(botBlockedDescriptions...)
+	case ErrChatNotFound:
+		return e.Code == http.StatusBadRequest && e.hasDescription("chat not found")
+	case ErrNotEnoughRights:
+		return (e.Code == http.StatusForbidden || e.Code == http.StatusBadRequest) && e.hasDescription("not enough rights")
+	case ErrServerError:
+		return e.Code >= http.StatusInternalServerError
+	}
+
+	return false
+}
+
+// Check if error description contains any of the given phrases.
+func (e *ApiError) hasDescription(phrases ...string) bool {
+	description := strings.ToLower(e.Description)
+
+	for _, phrase := range phrases {
+		if strings.Contains(description, phrase) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Check if messages can't be delivered to chat anymore (bot is blocked, kicked or chat is deleted).
+func IsChatUnavailable(err error) bool {
+	return errors.Is(err, ErrBotBlocked) || errors.Is(err, ErrChatNotFound)
+}
+
+// Check if request could be repeated later.
+func (e *ApiError) IsRetryable() bool {
+	return errors.Is(e, ErrTooManyRequests) || errors.Is(e, ErrServerError)
+}
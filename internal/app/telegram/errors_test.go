@@ -0,0 +1,57 @@
+package telegram_test
+
+import (
+	"bot/internal/app/telegram"
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestIsChatUnavailable(t *testing.T) {
+	errors := map[error]bool{
+		&telegram.ApiError{Code: 403, Description: "Forbidden: bot was blocked by the user"}:                            true,
+		&telegram.ApiError{Code: 403, Description: "Forbidden: user is deactivated"}:                                    true,
+		&telegram.ApiError{Code: 403, Description: "Forbidden: bot was kicked from the group chat"}:                     true,
+		&telegram.ApiError{Code: 403, Description: "Forbidden: not enough rights to send text messages"}:                false,
+		&telegram.ApiError{Code: 400, Description: "Bad Request: chat not found"}:                                       true,
+		&telegram.ApiError{Code: 400, Description: "Bad Request: message text is empty"}:                                false,
+		&telegram.ApiError{Code: 429, Description: "Too Many Requests: retry after 5"}:                                  false,
+		fmt.Errorf("wrapped: %w", &telegram.ApiError{Code: 403, Description: "Forbidden: bot was blocked by the user"}): true,
+		fmt.Errorf("connection refused"): false,
+	}
+
+	for err, expected := range errors {
+		if got := telegram.IsChatUnavailable(err); got != expected {
+			t.Errorf("Invalid result for \"%v\", got: %v, instead of: %v.", err, got, expected)
+		}
+	}
+}
+
+func TestNotEnoughRights(t *testing.T) {
+	err := fmt.Errorf("wrapped: %w", &telegram.ApiError{Code: 400, Description: "Bad Request: not enough rights to send text messages to the chat"})
+
+	if !errors.Is(err, telegram.ErrNotEnoughRights) {
+		t.Errorf("Invalid error, got: %v, instead of: %v.", err, telegram.ErrNotEnoughRights)
+	}
+
+	if errors.Is(err, telegram.ErrBotBlocked) {
+		t.Errorf("Missing rights are treated as blocked bot: %v.", err)
+	}
+}
+
+func TestChatMemberHasLeft(t *testing.T) {
+	statuses := map[string]bool{
+		telegram.ChatMemberStatusMember:        false,
+		telegram.ChatMemberStatusAdministrator: false,
+		telegram.ChatMemberStatusLeft:          true,
+		telegram.ChatMemberStatusKicked:        true,
+	}
+
+	for status, expected := range statuses {
+		member := telegram.ChatMember{Status: status}
+
+		if got := member.HasLeft(); got != expected {
+			t.Errorf("Invalid result for \"%s\", got: %v, instead of: %v.", status, got, expected)
+		}
+	}
+}
@@ -0,0 +1,180 @@
+package telegram
+
+import (
+	"bot/internal/app/database"
+	"bot/internal/app/helpers"
+	"bot/internal/app/logger"
+	"bot/internal/app/statemachine"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// Conversations survive restarts, state machine is restored by the given constructor.
+type PostgresConversationStore struct {
+	db              *database.Postgres
+	logger          logger.LoggerInterface
+	newStateMachine func() statemachine.StateMachine
+}
+
+func NewPostgresConversationStore(db *database.Postgres, logger logger.LoggerInterface, newStateMachine func() statemachine.StateMachine) PostgresConversationStore {
+	return PostgresConversationStore{
+		db:              db,
+		logger:          logger,
+		newStateMachine: newStateMachine,
+	}
+}
+
+func (s *PostgresConversationStore) Find(hash string) (*Conversation, bool) {
+	sql := "SELECT chat_id, from_user, last_message, last_callback_query_id, state, data, updated_at" +
+		" FROM conversations WHERE hash = @hash"
+
+	args := pgx.NamedArgs{
+		"hash": hash,
+	}
+
+	var conversation Conversation
+	var state string
+	var user, lastMessage, data []byte
+
+	err := s.db.Connection.QueryRow(s.db.Context, sql, args).Scan(
+		&conversation.ChatId,
+		&user,
+		&lastMessage,
+		&conversation.LastCallbackQueryId,
+		&state,
+		&data,
+		&conversation.UpdatedAt,
+	)
+
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, false
+	}
+
+	if err != nil {
+		s.logger.Println("Unable to find conversation:", err)
+		return nil, false
+	}
+
+	err = errors.Join(
+		json.Unmarshal(user, &conversation.User),
+		json.Unmarshal(lastMessage, &conversation.LastMessage),
+		json.Unmarshal(data, &conversation.Data),
+	)
+
+	if err != nil {
+		s.logger.Println("Unable to decode conversation:", err)
+		return nil, false
+	}
+
+	// empty state means that state machine was never initialized
+	if state != "" {
+		conversation.StateMachine = s.newStateMachine()
+
+		if err := conversation.StateMachine.Restore(statemachine.State(state)); err != nil {
+			s.logger.Println("Unable to restore conversation state", state, ":", err)
+			conversation.Reset()
+		}
+	}
+
+	return &conversation, true
+}
+
+func (s *PostgresConversationStore) Save(hash string, conversation *Conversation) error {
+	user, err := json.Marshal(conversation.User)
+	if err != nil {
+		return err
+	}
+
+	lastMessage, err := json.Marshal(conversation.LastMessage)
+	if err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(conversation.Data)
+	if err != nil {
+		return err
+	}
+
+	state := ""
+	if conversation.StateMachine.IsInitialized() {
+		state = string(conversation.StateMachine.GetCurrentState())
+	}
+
+	conversation.UpdatedAt = time.Now()
+
+	sql := `INSERT INTO conversations (
+		hash,
+		chat_id,
+		from_user,
+		last_message,
+		last_callback_query_id,
+		state,
+		data,
+		updated_at
+	) VALUES (
+		@hash,
+		@chat_id,
+		@from_user,
+		@last_message,
+		@last_callback_query_id,
+		@state,
+		@data,
+		@updated_at
+	) ON CONFLICT (hash) DO UPDATE SET
+		chat_id = EXCLUDED.chat_id,
+		from_user = EXCLUDED.from_user,
+		last_message = EXCLUDED.last_message,
+		last_callback_query_id = EXCLUDED.last_callback_query_id,
+		state = EXCLUDED.state,
+		data = EXCLUDED.data,
+		updated_at = EXCLUDED.updated_at`
+
+	args := pgx.NamedArgs{
+		"hash":                   hash,
+		"chat_id":                conversation.ChatId,
+		"from_user":              user,
+		"last_message":           lastMessage,
+		"last_callback_query_id": conversation.LastCallbackQueryId,
+		"state":                  state,
+		"data":                   data,
+		"updated_at":             helpers.TimeToDatabase(conversation.UpdatedAt),
+	}
+
+	_, err = s.db.Connection.Exec(s.db.Context, sql, args)
+
+	return err
+}
+
+func (s *PostgresConversationStore) Delete(hash string) error {
+	sql := "DELETE FROM conversations WHERE hash = @hash"
+
+	args := pgx.NamedArgs{
+		"hash": hash,
+	}
+
+	_, err := s.db.Connection.Exec(s.db.Context, sql, args)
+
+	return err
+}
+
+// Delete conversations which are not updated since the given time, except the ones in kept states (e.g. waiting for a job).
+func (s *PostgresConversationStore) DeleteInactive(updatedBefore time.Time, keptStates ...statemachine.State) error {
+	sql := "DELETE FROM conversations WHERE updated_at < @updated_at AND NOT (state = ANY(@kept_states))"
+
+	states := make([]string, len(keptStates))
+	for i, state := range keptStates {
+		states[i] = string(state)
+	}
+
+	args := pgx.NamedArgs{
+		"updated_at":  helpers.TimeToDatabase(updatedBefore),
+		"kept_states": states,
+	}
+
+	_, err := s.db.Connection.Exec(s.db.Context, sql, args)
+
+	return err
+}
@@ -0,0 +1,137 @@
+package telegram
+
+import (
+	"bot/internal/app/database"
+	"bot/internal/app/helpers"
+	"bot/internal/app/logger"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+type PostgresUpdateRepository struct {
+	db     *database.Postgres
+	logger logger.LoggerInterface
+}
+
+func NewPostgresUpdateRepository(db *database.Postgres, logger logger.LoggerInterface) PostgresUpdateRepository {
+	return PostgresUpdateRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// Get id of the latest received update (0 if there are none).
+func (r *PostgresUpdateRepository) GetLastUpdateId() int {
+	sql := "SELECT COALESCE(MAX(update_id), 0) FROM telegram_updates"
+
+	row := r.db.Connection.QueryRow(r.db.Context, sql)
+
+	updateId := 0
+	if err := row.Scan(&updateId); err != nil {
+		r.logger.Println("Unable to get last update id:", err)
+	}
+
+	return updateId
+}
+
+// Find updates which were received, but not processed (oldest first).
+func (r *PostgresUpdateRepository) FindUnprocessed() []Update {
+	sql := "SELECT payload FROM telegram_updates WHERE processed_at IS NULL ORDER BY update_id ASC"
+
+	rows, err := r.db.Connection.Query(r.db.Context, sql)
+	if err != nil {
+		r.logger.Println("Unable to execute query:", err)
+		return nil
+	}
+
+	updates, err := pgx.CollectRows[Update](rows, func(row pgx.CollectableRow) (Update, error) {
+		var update Update
+		var payload []byte
+
+		if err := row.Scan(&payload); err != nil {
+			return update, err
+		}
+
+		err := json.Unmarshal(payload, &update)
+
+		return update, err
+	})
+
+	if err != nil {
+		r.logger.Println("Unable to collect rows:", err)
+		return nil
+	}
+
+	return updates
+}
+
+// Store received update, returns "false" if it's already stored (processed or not).
+func (r *PostgresUpdateRepository) Save(update Update) (bool, error) {
+	payload, err := json.Marshal(update)
+	if err != nil {
+		return false, err
+	}
+
+	// nothing is returned if update already exists
+	sql := `INSERT INTO telegram_updates (
+		update_id,
+		payload,
+		received_at
+	) VALUES (
+		@update_id,
+		@payload,
+		@received_at
+	) ON CONFLICT (update_id) DO NOTHING
+	RETURNING update_id`
+
+	args := pgx.NamedArgs{
+		"update_id":   update.UpdateId,
+		"payload":     payload,
+		"received_at": helpers.TimeToDatabase(time.Now()),
+	}
+
+	var updateId int
+
+	err = r.db.Connection.QueryRow(r.db.Context, sql, args).Scan(&updateId)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return false, nil
+	}
+
+	if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// Mark update as processed.
+func (r *PostgresUpdateRepository) MarkProcessed(updateId int) error {
+	sql := "UPDATE telegram_updates SET processed_at = @processed_at WHERE update_id = @update_id"
+
+	args := pgx.NamedArgs{
+		"update_id":    updateId,
+		"processed_at": helpers.TimeToDatabase(time.Now()),
+	}
+
+	_, err := r.db.Connection.Exec(r.db.Context, sql, args)
+
+	return err
+}
+
+// Delete processed updates received before the given time, the latest one is kept to restore offset.
+func (r *PostgresUpdateRepository) DeleteProcessedBefore(receivedAt time.Time) error {
+	sql := "DELETE FROM telegram_updates" +
+		" WHERE processed_at IS NOT NULL AND received_at < @received_at" +
+		" AND update_id < (SELECT MAX(update_id) FROM telegram_updates)"
+
+	args := pgx.NamedArgs{
+		"received_at": helpers.TimeToDatabase(receivedAt),
+	}
+
+	_, err := r.db.Connection.Exec(r.db.Context, sql, args)
+
+	return err
+}
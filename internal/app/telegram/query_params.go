@@ -35,6 +35,20 @@ func (p *SendMessageParams) ToString() string {
 	return data.Encode()
 }
 
+// Query parameters for "sendPhoto" method.
+// https://core.telegram.org/bots/api#sendphoto
+type SendPhotoParams struct {
+	ChatId int
+}
+
+func (p *SendPhotoParams) ToString() string {
+	data := make(url.Values)
+
+	data.Add("chat_id", strconv.Itoa(p.ChatId))
+
+	return data.Encode()
+}
+
 // Query parameters for "answerCallbackQuery" method.
 // https://core.telegram.org/bots/api#answercallbackquery
 type AnswerCallbackQueryParams struct {
@@ -80,3 +94,19 @@ func (p *SetMessageReactionParams) ToString() string {
 
 	return data.Encode()
 }
+
+// Query parameters for "getChatMember" method.
+// https://core.telegram.org/bots/api#getchatmember
+type GetChatMemberParams struct {
+	ChatId int
+	UserId int
+}
+
+func (p *GetChatMemberParams) ToString() string {
+	data := make(url.Values)
+
+	data.Add("chat_id", strconv.Itoa(p.ChatId))
+	data.Add("user_id", strconv.Itoa(p.UserId))
+
+	return data.Encode()
+}
@@ -0,0 +1,146 @@
+package telegram
+
+import (
+	"sync"
+	"time"
+)
+
+// Bot API limits, see https://core.telegram.org/bots/faq#my-bot-is-hitting-limits-how-do-i-avoid-this
+const (
+	globalRequestsPerSecond  = 30
+	chatRequestsPerSecond    = 1
+	chatRequestsBurst        = 3
+	groupRequestsPerSecond   = 20.0 / 60
+	groupRequestsBurst       = 3
+	rateLimiterChatsCapacity = 1000
+)
+
+// Token bucket, which is refilled with a constant rate up to its capacity.
+type tokenBucket struct {
+	rate     float64
+	capacity float64
+	tokens   float64
+	updated  time.Time
+}
+
+func newTokenBucket(rate float64, capacity float64, now time.Time) *tokenBucket {
+	return &tokenBucket{
+		rate:     rate,
+		capacity: capacity,
+		tokens:   capacity,
+		updated:  now,
+	}
+}
+
+// Take a token and get delay after which it's available (zero if it's available right away).
+func (b *tokenBucket) reserve(now time.Time) time.Duration {
+	b.refill(now)
+
+	b.tokens--
+
+	if b.tokens >= 0 {
+		return 0
+	}
+
+	return time.Duration(-b.tokens / b.rate * float64(time.Second))
+}
+
+// Check if bucket is full, so it's the same as a new one.
+func (b *tokenBucket) isFull(now time.Time) bool {
+	b.refill(now)
+
+	return b.tokens >= b.capacity
+}
+
+func (b *tokenBucket) refill(now time.Time) {
+	if now.After(b.updated) {
+		b.tokens = min(b.capacity, b.tokens+now.Sub(b.updated).Seconds()*b.rate)
+		b.updated = now
+	}
+}
+
+// Limiter of outgoing requests, it keeps global and per chat limits (group chats have a lower one).
+type RateLimiter struct {
+	global     *tokenBucket
+	chats      map[int]*tokenBucket
+	chatRate   float64
+	chatBurst  float64
+	groupRate  float64
+	groupBurst float64
+	locker     sync.Mutex
+	now        func() time.Time
+	sleep      func(duration time.Duration)
+}
+
+func NewRateLimiter(globalRate float64, chatRate float64, chatBurst float64, groupRate float64, groupBurst float64) *RateLimiter {
+	return &RateLimiter{
+		global:     newTokenBucket(globalRate, globalRate, time.Now()),
+		chats:      make(map[int]*tokenBucket),
+		chatRate:   chatRate,
+		chatBurst:  chatBurst,
+		groupRate:  groupRate,
+		groupBurst: groupBurst,
+		now:        time.Now,
+		sleep:      time.Sleep,
+	}
+}
+
+// Block until message to chat is allowed.
+// Waiting requests are served in order of their arrival.
+func (l *RateLimiter) Wait(chatId int) {
+	l.sleepFor(l.reserve(chatId))
+}
+
+// Block until request, which is not limited per chat (e.g. message edit or callback answer), is allowed.
+func (l *RateLimiter) WaitGlobal() {
+	l.sleepFor(l.reserveGlobal())
+}
+
+func (l *RateLimiter) sleepFor(delay time.Duration) {
+	if delay > 0 {
+		l.sleep(delay)
+	}
+}
+
+// Reserve a slot for request, which is limited only globally, and get delay before it.
+func (l *RateLimiter) reserveGlobal() time.Duration {
+	l.locker.Lock()
+	defer l.locker.Unlock()
+
+	return l.global.reserve(l.now())
+}
+
+// Reserve a slot for message to chat and get delay before it.
+func (l *RateLimiter) reserve(chatId int) time.Duration {
+	l.locker.Lock()
+	defer l.locker.Unlock()
+
+	now := l.now()
+	delay := l.global.reserve(now)
+
+	if len(l.chats) >= rateLimiterChatsCapacity {
+		l.forgetIdleChats(now)
+	}
+
+	chat, exists := l.chats[chatId]
+	if !exists {
+		chat = newTokenBucket(l.chatRate, l.chatBurst, now)
+
+		if IsGroupChatId(chatId) {
+			chat = newTokenBucket(l.groupRate, l.groupBurst, now)
+		}
+
+		l.chats[chatId] = chat
+	}
+
+	return max(delay, chat.reserve(now))
+}
+
+// Remove buckets of chats which have no recent requests.
+func (l *RateLimiter) forgetIdleChats(now time.Time) {
+	for chatId, chat := range l.chats {
+		if chat.isFull(now) {
+			delete(l.chats, chatId)
+		}
+	}
+}
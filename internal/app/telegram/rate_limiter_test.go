@@ -0,0 +1,81 @@
+package telegram
+
+import (
+	"testing"
+	"time"
+)
+
+// Rate limiter with fake clock, which is moved forward on sleep.
+func newTestRateLimiter(globalRate float64, chatRate float64, chatBurst float64) (*RateLimiter, *time.Time) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	limiter := NewRateLimiter(globalRate, chatRate, chatBurst, chatRate/3, chatBurst)
+	limiter.global = newTokenBucket(globalRate, globalRate, now)
+	limiter.now = func() time.Time { return now }
+	limiter.sleep = func(duration time.Duration) { now = now.Add(duration) }
+
+	return limiter, &now
+}
+
+func TestRateLimiterChatLimit(t *testing.T) {
+	limiter, _ := newTestRateLimiter(30, 1, 3)
+
+	expected := []time.Duration{0, 0, 0, time.Second, 2 * time.Second}
+
+	for i, delay := range expected {
+		if got := limiter.reserve(42); got != delay {
+			t.Errorf("Invalid delay of request #%d, got: %v, instead of: %v.", i+1, got, delay)
+		}
+	}
+
+	// other chats are not affected
+	if got := limiter.reserve(43); got != 0 {
+		t.Errorf("Invalid delay of another chat, got: %v, instead of: %v.", got, 0)
+	}
+}
+
+func TestRateLimiterGlobalLimit(t *testing.T) {
+	limiter, _ := newTestRateLimiter(2, 1, 1)
+
+	limiter.reserve(1)
+	limiter.reserve(2)
+
+	if got := limiter.reserve(3); got != 500*time.Millisecond {
+		t.Errorf("Invalid delay, got: %v, instead of: %v.", got, 500*time.Millisecond)
+	}
+}
+
+func TestRateLimiterWaitRefillsTokens(t *testing.T) {
+	limiter, now := newTestRateLimiter(30, 1, 1)
+	start := *now
+
+	for range 3 {
+		limiter.Wait(42)
+	}
+
+	if elapsed := now.Sub(start); elapsed != 2*time.Second {
+		t.Errorf("Invalid elapsed time, got: %v, instead of: %v.", elapsed, 2*time.Second)
+	}
+
+	// chat is idle long enough, so no delay is expected
+	*now = now.Add(time.Minute)
+
+	if got := limiter.reserve(42); got != 0 {
+		t.Errorf("Invalid delay after idle, got: %v, instead of: %v.", got, 0)
+	}
+}
+
+func TestRateLimiterGroupLimit(t *testing.T) {
+	limiter, _ := newTestRateLimiter(30, 1, 1)
+
+	limiter.reserve(-100)
+
+	if got := limiter.reserve(-100); got != 3*time.Second {
+		t.Errorf("Invalid delay of group, got: %v, instead of: %v.", got, 3*time.Second)
+	}
+
+	// requests which are limited only globally don't use chat limits
+	if got := limiter.reserveGlobal(); got != 0 {
+		t.Errorf("Invalid delay of global request, got: %v, instead of: %v.", got, 0)
+	}
+}
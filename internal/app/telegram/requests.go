@@ -1,6 +1,10 @@
 package telegram
 
-import "encoding/json"
+import (
+	"bytes"
+	"encoding/json"
+	"mime/multipart"
+)
 
 const parseModeHtml = "HTML"
 
@@ -13,6 +17,8 @@ type SendMessageRequest struct {
 	Text               string
 	ReplyMarkup        InlineKeyboardMarkup
 	LinkPreviewOptions LinkPreviewOptions
+	// Show reply interface to the author of replied message (or to mentioned users), ignored with inline keyboard.
+	ForceReply bool
 }
 
 func (r *SendMessageRequest) ToJson() ([]byte, error) {
@@ -30,11 +36,100 @@ func (r *SendMessageRequest) ToJson() ([]byte, error) {
 
 	if len(r.ReplyMarkup.Keyboard) > 0 {
 		data["reply_markup"] = r.ReplyMarkup
+	} else if r.ForceReply {
+		// https://core.telegram.org/bots/api#forcereply
+		data["reply_markup"] = JsonObject{
+			"force_reply": true,
+			"selective":   true,
+		}
+	}
+
+	return json.Marshal(data)
+}
+
+// Request data for "setWebhook" method.
+// https://core.telegram.org/bots/api#setwebhook
+type SetWebhookRequest struct {
+	Url    string
+	Secret string
+}
+
+func (r *SetWebhookRequest) ToJson() ([]byte, error) {
+	data := JsonObject{
+		"url":          r.Url,
+		"secret_token": r.Secret,
 	}
 
 	return json.Marshal(data)
 }
 
+// Request data for "sendPhoto" method.
+// https://core.telegram.org/bots/api#sendphoto
+type SendPhotoRequest struct {
+	ReplyToMessageId int
+	Photo            []byte
+	FileName         string
+	Caption          string
+	ReplyMarkup      InlineKeyboardMarkup
+}
+
+func (r *SendPhotoRequest) ToMultipart() (*bytes.Buffer, string, error) {
+	body := bytes.NewBuffer(nil)
+	writer := multipart.NewWriter(body)
+
+	fields := map[string]string{
+		"caption":    r.Caption,
+		"parse_mode": parseModeHtml,
+	}
+
+	if r.ReplyToMessageId > 0 {
+		replyParameters, err := json.Marshal(JsonObject{
+			"message_id": r.ReplyToMessageId,
+		})
+
+		if err != nil {
+			return nil, "", err
+		}
+
+		fields["reply_parameters"] = string(replyParameters)
+	}
+
+	if len(r.ReplyMarkup.Keyboard) > 0 {
+		replyMarkup, err := json.Marshal(r.ReplyMarkup)
+		if err != nil {
+			return nil, "", err
+		}
+
+		fields["reply_markup"] = string(replyMarkup)
+	}
+
+	for name, value := range fields {
+		if err := writer.WriteField(name, value); err != nil {
+			return nil, "", err
+		}
+	}
+
+	fileName := r.FileName
+	if fileName == "" {
+		fileName = "photo.png"
+	}
+
+	part, err := writer.CreateFormFile("photo", fileName)
+	if err != nil {
+		return nil, "", err
+	}
+
+	if _, err := part.Write(r.Photo); err != nil {
+		return nil, "", err
+	}
+
+	if err := writer.Close(); err != nil {
+		return nil, "", err
+	}
+
+	return body, writer.FormDataContentType(), nil
+}
+
 // Request data for "editMessageText" method.
 // https://core.telegram.org/bots/api#editmessagetext
 type EditMessageRequest struct {
@@ -1,5 +1,12 @@
 package telegram
 
+import (
+	"bot/internal/app/helpers"
+	"html"
+	"strconv"
+	"strings"
+)
+
 // https://core.telegram.org/bots/api#user
 type User struct {
 	Id        int    `json:"id"`
@@ -9,6 +16,28 @@ type User struct {
 	UserName  string `json:"username"`
 }
 
+// Get display name of user: full name, or username if user has no name.
+func (u User) GetName() string {
+	name := strings.TrimSpace(helpers.ConcatStrings(u.FirstName, " ", u.LastName))
+
+	if name == "" && u.UserName != "" {
+		name = helpers.ConcatStrings("@", u.UserName)
+	}
+
+	return name
+}
+
+// Get HTML mention of user, it works even if user has no username.
+func (u User) GetMention() string {
+	name := u.GetName()
+
+	if name == "" {
+		name = strconv.Itoa(u.Id)
+	}
+
+	return helpers.ConcatStrings("<a href=\"tg://user?id=", strconv.Itoa(u.Id), "\">", html.EscapeString(name), "</a>")
+}
+
 // https://core.telegram.org/bots/api#user
 type BotUser struct {
 	User
@@ -23,7 +52,23 @@ type Chat struct {
 	FirstName string `json:"first_name"`
 	LastName  string `json:"last_name"`
 	UserName  string `json:"username"`
-	Type      string `json:"private"`
+	Type      string `json:"type"`
+}
+
+const (
+	ChatTypePrivate    = "private"
+	ChatTypeGroup      = "group"
+	ChatTypeSupergroup = "supergroup"
+	ChatTypeChannel    = "channel"
+)
+
+func (c Chat) IsGroup() bool {
+	return c.Type == ChatTypeGroup || c.Type == ChatTypeSupergroup
+}
+
+// Check if chat id belongs to group (ids of groups are negative, unlike ids of users).
+func IsGroupChatId(chatId int) bool {
+	return chatId < 0
 }
 
 // https://core.telegram.org/bots/api#message
@@ -37,9 +82,10 @@ type Message struct {
 
 // https://core.telegram.org/bots/api#update
 type Update struct {
-	UpdateId      int           `json:"update_id"`
-	Message       Message       `json:"message"`
-	CallbackQuery CallbackQuery `json:"callback_query"`
+	UpdateId      int               `json:"update_id"`
+	Message       Message           `json:"message"`
+	CallbackQuery CallbackQuery     `json:"callback_query"`
+	MyChatMember  ChatMemberUpdated `json:"my_chat_member"`
 }
 
 // https://core.telegram.org/bots/api#callbackquery
@@ -51,6 +97,39 @@ type CallbackQuery struct {
 	Data         string  `json:"data"`
 }
 
+// https://core.telegram.org/bots/api#chatmemberupdated
+type ChatMemberUpdated struct {
+	Chat          Chat       `json:"chat"`
+	From          User       `json:"from"`
+	Date          int        `json:"date"`
+	OldChatMember ChatMember `json:"old_chat_member"`
+	NewChatMember ChatMember `json:"new_chat_member"`
+}
+
+// https://core.telegram.org/bots/api#chatmember
+type ChatMember struct {
+	Status string `json:"status"`
+	User   User   `json:"user"`
+}
+
+const (
+	ChatMemberStatusCreator       = "creator"
+	ChatMemberStatusAdministrator = "administrator"
+	ChatMemberStatusMember        = "member"
+	ChatMemberStatusRestricted    = "restricted"
+	ChatMemberStatusLeft          = "left"
+	ChatMemberStatusKicked        = "kicked"
+)
+
+// Check if member has left the chat or was kicked (e.g. user has blocked the bot in private chat).
+func (m ChatMember) HasLeft() bool {
+	return m.Status == ChatMemberStatusLeft || m.Status == ChatMemberStatusKicked
+}
+
+func (m ChatMember) IsAdmin() bool {
+	return m.Status == ChatMemberStatusCreator || m.Status == ChatMemberStatusAdministrator
+}
+
 // https://core.telegram.org/bots/api#inlinekeyboardmarkup
 type InlineKeyboardMarkup struct {
 	Keyboard [][]InlineKeyboardButton `json:"inline_keyboard"`
@@ -59,8 +138,8 @@ type InlineKeyboardMarkup struct {
 // https://core.telegram.org/bots/api#inlinekeyboardbutton
 type InlineKeyboardButton struct {
 	Text         string `json:"text"`
-	Url          string `json:"url"`
-	CallbackData string `json:"callback_data"`
+	Url          string `json:"url,omitempty"`
+	CallbackData string `json:"callback_data,omitempty"`
 }
 
 // https://core.telegram.org/bots/api#linkpreviewoptions
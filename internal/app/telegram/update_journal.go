@@ -0,0 +1,88 @@
+package telegram
+
+import (
+	"bot/internal/app/logger"
+	"time"
+)
+
+type UpdateRepository interface {
+	GetLastUpdateId() int
+	FindUnprocessed() []Update
+	Save(update Update) (bool, error)
+	MarkProcessed(updateId int) error
+	DeleteProcessedBefore(receivedAt time.Time) error
+}
+
+const updateJournalRetention = 7 * 24 * time.Hour
+
+// Durable log of received updates, which makes processing of each update at-least-once:
+// update is stored before processing, so it's replayed after restart if processing was interrupted.
+type UpdateJournal struct {
+	repository UpdateRepository
+	logger     logger.LoggerInterface
+}
+
+func NewUpdateJournal(repository UpdateRepository, logger logger.LoggerInterface) UpdateJournal {
+	return UpdateJournal{
+		repository: repository,
+		logger:     logger,
+	}
+}
+
+// Get offset to request new updates from.
+func (j *UpdateJournal) GetOffset() int {
+	lastUpdateId := j.repository.GetLastUpdateId()
+	if lastUpdateId == 0 {
+		return 0
+	}
+
+	return lastUpdateId + 1
+}
+
+// Find updates which were received, but not processed before restart.
+func (j *UpdateJournal) FindUnprocessed() []Update {
+	return j.repository.FindUnprocessed()
+}
+
+// Store update before processing, returns "false" if it's already stored and must be skipped.
+func (j *UpdateJournal) Receive(update Update) bool {
+	isNew, err := j.Store(update)
+
+	// it's better to process update than to lose it
+	if err != nil {
+		j.logger.Println("Unable to store update", update.UpdateId, ":", err)
+		return true
+	}
+
+	return isNew
+}
+
+// Store update, e.g. before it's acknowledged to Telegram, returns "false" if it's already stored.
+// Stored update is either queued already or replayed after restart, so it must not be queued twice.
+func (j *UpdateJournal) Store(update Update) (bool, error) {
+	isNew, err := j.repository.Save(update)
+	if err != nil {
+		return false, err
+	}
+
+	if !isNew {
+		j.logger.Println("Skipping duplicate update", update.UpdateId)
+		return false, nil
+	}
+
+	return true, nil
+}
+
+// Mark update as processed, so it's not replayed after restart.
+func (j *UpdateJournal) MarkProcessed(update Update) {
+	if err := j.repository.MarkProcessed(update.UpdateId); err != nil {
+		j.logger.Println("Unable to mark update", update.UpdateId, "as processed:", err)
+	}
+}
+
+// Delete old processed updates.
+func (j *UpdateJournal) Cleanup() {
+	if err := j.repository.DeleteProcessedBefore(time.Now().Add(-updateJournalRetention)); err != nil {
+		j.logger.Println("Unable to delete old updates:", err)
+	}
+}
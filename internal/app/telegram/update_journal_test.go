@@ -0,0 +1,127 @@
+package telegram_test
+
+import (
+	"bot/internal/app/telegram"
+	"sort"
+	"testing"
+	"time"
+)
+
+type memoryUpdateRepository struct {
+	updates   map[int]telegram.Update
+	processed map[int]bool
+}
+
+func newMemoryUpdateRepository() *memoryUpdateRepository {
+	return &memoryUpdateRepository{
+		updates:   make(map[int]telegram.Update),
+		processed: make(map[int]bool),
+	}
+}
+
+func (r *memoryUpdateRepository) GetLastUpdateId() int {
+	lastUpdateId := 0
+
+	for updateId := range r.updates {
+		lastUpdateId = max(lastUpdateId, updateId)
+	}
+
+	return lastUpdateId
+}
+
+func (r *memoryUpdateRepository) FindUnprocessed() []telegram.Update {
+	var updates []telegram.Update
+
+	for updateId, update := range r.updates {
+		if !r.processed[updateId] {
+			updates = append(updates, update)
+		}
+	}
+
+	sort.Slice(updates, func(i int, j int) bool {
+		return updates[i].UpdateId < updates[j].UpdateId
+	})
+
+	return updates
+}
+
+func (r *memoryUpdateRepository) Save(update telegram.Update) (bool, error) {
+	if _, exists := r.updates[update.UpdateId]; exists {
+		return false, nil
+	}
+
+	r.updates[update.UpdateId] = update
+
+	return true, nil
+}
+
+func (r *memoryUpdateRepository) MarkProcessed(updateId int) error {
+	r.processed[updateId] = true
+
+	return nil
+}
+
+func (r *memoryUpdateRepository) DeleteProcessedBefore(receivedAt time.Time) error {
+	return nil
+}
+
+func TestUpdateJournalSkipsDuplicates(t *testing.T) {
+	repository := newMemoryUpdateRepository()
+	journal := telegram.NewUpdateJournal(repository, silentLogger{})
+
+	var processed []int
+
+	for _, updateId := range []int{10, 11, 10, 12, 11} {
+		update := telegram.Update{UpdateId: updateId}
+
+		if !journal.Receive(update) {
+			continue
+		}
+
+		processed = append(processed, update.UpdateId)
+		journal.MarkProcessed(update)
+	}
+
+	if len(processed) != 3 {
+		t.Fatalf("Invalid count of processed updates, got: %d (%v), instead of: %d.", len(processed), processed, 3)
+	}
+
+	if offset := journal.GetOffset(); offset != 13 {
+		t.Errorf("Invalid offset, got: %d, instead of: %d.", offset, 13)
+	}
+}
+
+func TestUpdateJournalFindsUnprocessed(t *testing.T) {
+	repository := newMemoryUpdateRepository()
+	journal := telegram.NewUpdateJournal(repository, silentLogger{})
+
+	// update 21 was stored, but processing was interrupted
+	journal.Receive(telegram.Update{UpdateId: 20})
+	journal.MarkProcessed(telegram.Update{UpdateId: 20})
+	journal.Receive(telegram.Update{UpdateId: 21})
+
+	unprocessed := journal.FindUnprocessed()
+
+	if len(unprocessed) != 1 || unprocessed[0].UpdateId != 21 {
+		t.Fatalf("Invalid unprocessed updates, got: %v.", unprocessed)
+	}
+
+	// Telegram could send the update again if offset was not confirmed, but it's replayed already
+	if journal.Receive(telegram.Update{UpdateId: 21}) {
+		t.Errorf("Unprocessed update must be skipped.")
+	}
+
+	journal.MarkProcessed(telegram.Update{UpdateId: 21})
+
+	if journal.Receive(telegram.Update{UpdateId: 21}) {
+		t.Errorf("Processed update must be skipped.")
+	}
+}
+
+func TestUpdateJournalOffsetWithoutUpdates(t *testing.T) {
+	journal := telegram.NewUpdateJournal(newMemoryUpdateRepository(), silentLogger{})
+
+	if offset := journal.GetOffset(); offset != 0 {
+		t.Errorf("Invalid offset, got: %d, instead of: %d.", offset, 0)
+	}
+}
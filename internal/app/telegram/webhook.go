@@ -0,0 +1,175 @@
+package telegram
+
+import (
+	"bot/internal/app/logger"
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+const (
+	webhookSecretHeader    = "X-Telegram-Bot-Api-Secret-Token"
+	webhookMaxBodySize     = 1 << 20
+	webhookQueueSize       = 100
+	webhookShutdownTimeout = 5 * time.Second
+)
+
+var ErrWebhookSecretRequired = errors.New("webhook secret is required")
+
+type WebhookConfig struct {
+	// Public HTTPS URL which Telegram sends updates to.
+	Url string
+
+	// Secret token which Telegram puts to every request, allowed characters: A-Z, a-z, 0-9, _ and -.
+	Secret string
+
+	// Address of embedded HTTP server (e.g. ":8080").
+	ListenAddress string
+}
+
+// HTTP handler which accepts updates pushed by Telegram and puts them to the queue.
+// Update is stored to the journal before it's acknowledged, so it's replayed after restart if it's still in the queue.
+type WebhookHandler struct {
+	secret  string
+	journal *UpdateJournal
+	updates chan<- Update
+	logger  logger.LoggerInterface
+}
+
+func NewWebhookHandler(secret string, journal *UpdateJournal, updates chan<- Update, logger logger.LoggerInterface) *WebhookHandler {
+	return &WebhookHandler{
+		secret:  secret,
+		journal: journal,
+		updates: updates,
+		logger:  logger,
+	}
+}
+
+func (h *WebhookHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+
+	secret := r.Header.Get(webhookSecretHeader)
+	if subtle.ConstantTimeCompare([]byte(secret), []byte(h.secret)) != 1 {
+		h.logger.Println("Webhook request with invalid secret from", r.RemoteAddr)
+		http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+		return
+	}
+
+	var update Update
+
+	decoder := json.NewDecoder(http.MaxBytesReader(w, r.Body, webhookMaxBodySize))
+	if err := decoder.Decode(&update); err != nil {
+		h.logger.Println("Unable to decode webhook update:", err)
+		http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		return
+	}
+
+	// Telegram resends update if it's not acknowledged
+	isNew, err := h.journal.Store(update)
+	if err != nil {
+		h.logger.Println("Unable to store webhook update", update.UpdateId, ":", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	if !isNew {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	select {
+	case h.updates <- update:
+		w.WriteHeader(http.StatusOK)
+	case <-r.Context().Done():
+		http.Error(w, http.StatusText(http.StatusServiceUnavailable), http.StatusServiceUnavailable)
+	}
+}
+
+// Receive updates via webhook and apply a callback function to each item (one by one, as with polling).
+// Updates are stored to the journal before callback, webhook is registered on start and deleted when context is cancelled.
+func (b *Bot) ListenForWebhook(ctx context.Context, config WebhookConfig, journal *UpdateJournal, callback func(update Update)) error {
+	if config.Secret == "" {
+		return ErrWebhookSecretRequired
+	}
+
+	webhookUrl, err := url.Parse(config.Url)
+	if err != nil {
+		return err
+	}
+
+	path := webhookUrl.Path
+	if path == "" {
+		path = "/"
+	}
+
+	updatesChannel := make(chan Update, webhookQueueSize)
+
+	mux := http.NewServeMux()
+	mux.Handle(path, NewWebhookHandler(config.Secret, journal, updatesChannel, b.logger))
+
+	server := &http.Server{
+		Addr:              config.ListenAddress,
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	serverErrors := make(chan error, 1)
+
+	go func() {
+		serverErrors <- server.ListenAndServe()
+	}()
+
+	if err := b.SetWebhook(config.Url, config.Secret); err != nil {
+		server.Close()
+		return err
+	}
+
+	b.logger.Println("Listening for webhook updates on", config.ListenAddress, path)
+
+	for {
+		select {
+		case update := <-updatesChannel:
+			callback(update)
+		case err := <-serverErrors:
+			return err
+		case <-ctx.Done():
+			shutdownCtx, cancel := context.WithTimeout(context.Background(), webhookShutdownTimeout)
+			defer cancel()
+
+			server.Shutdown(shutdownCtx)
+
+			return b.DeleteWebhook()
+		}
+	}
+}
+
+// Register webhook URL.
+// https://core.telegram.org/bots/api#setwebhook
+func (b *Bot) SetWebhook(webhookUrl string, secret string) error {
+	endpoint := b.getEndpoint("setWebhook", nil)
+
+	_, err := b.sendRequest(endpoint, &SetWebhookRequest{
+		Url:    webhookUrl,
+		Secret: secret,
+	}, true)
+
+	return err
+}
+
+// Remove webhook to switch back to polling.
+// https://core.telegram.org/bots/api#deletewebhook
+func (b *Bot) DeleteWebhook() error {
+	endpoint := b.getEndpoint("deleteWebhook", nil)
+
+	_, err := b.sendRequest(endpoint, nil, false)
+
+	return err
+}
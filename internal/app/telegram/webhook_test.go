@@ -0,0 +1,150 @@
+package telegram_test
+
+import (
+	"bot/internal/app/telegram"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+type silentLogger struct{}
+
+func (l silentLogger) Println(message ...any) {}
+
+type failingUpdateRepository struct {
+	*memoryUpdateRepository
+}
+
+func (r failingUpdateRepository) Save(update telegram.Update) (bool, error) {
+	return false, errors.New("connection refused")
+}
+
+func newWebhookTestHandler(repository telegram.UpdateRepository, updates chan telegram.Update) *telegram.WebhookHandler {
+	journal := telegram.NewUpdateJournal(repository, silentLogger{})
+
+	return telegram.NewWebhookHandler("s3cr3t", &journal, updates, silentLogger{})
+}
+
+func newWebhookTestRequest(body string) *http.Request {
+	request := httptest.NewRequest(http.MethodPost, "/webhook", strings.NewReader(body))
+	request.Header.Set("X-Telegram-Bot-Api-Secret-Token", "s3cr3t")
+
+	return request
+}
+
+func TestWebhookHandler(t *testing.T) {
+	repository := newMemoryUpdateRepository()
+	updates := make(chan telegram.Update, 1)
+	handler := newWebhookTestHandler(repository, updates)
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, newWebhookTestRequest(`{"update_id": 42, "message": {"text": "/help"}}`))
+
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("Invalid status, got: %d, instead of: %d.", recorder.Code, http.StatusOK)
+	}
+
+	update := <-updates
+	if update.UpdateId != 42 || update.Message.Text != "/help" {
+		t.Errorf("Invalid update, got: %+v.", update)
+	}
+
+	// acknowledged update is replayed after restart, even if it's not taken from the queue
+	if unprocessed := repository.FindUnprocessed(); len(unprocessed) != 1 || unprocessed[0].UpdateId != 42 {
+		t.Errorf("Update must be stored before it's acknowledged, got: %+v.", unprocessed)
+	}
+}
+
+func TestWebhookHandlerSkipsProcessedUpdates(t *testing.T) {
+	repository := newMemoryUpdateRepository()
+	repository.Save(telegram.Update{UpdateId: 42})
+	repository.MarkProcessed(42)
+
+	updates := make(chan telegram.Update, 1)
+	handler := newWebhookTestHandler(repository, updates)
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, newWebhookTestRequest(`{"update_id": 42}`))
+
+	if recorder.Code != http.StatusOK {
+		t.Errorf("Invalid status, got: %d, instead of: %d.", recorder.Code, http.StatusOK)
+	}
+
+	if len(updates) > 0 {
+		t.Errorf("Processed update must not be queued again.")
+	}
+}
+
+func TestWebhookHandlerSkipsPendingUpdates(t *testing.T) {
+	repository := newMemoryUpdateRepository()
+	updates := make(chan telegram.Update, 2)
+	handler := newWebhookTestHandler(repository, updates)
+
+	// Telegram resends update if acknowledgement is lost, while it's still in the queue
+	for range 2 {
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, newWebhookTestRequest(`{"update_id": 42}`))
+
+		if recorder.Code != http.StatusOK {
+			t.Errorf("Invalid status, got: %d, instead of: %d.", recorder.Code, http.StatusOK)
+		}
+	}
+
+	if len(updates) != 1 {
+		t.Errorf("Invalid count of queued updates, got: %d, instead of: %d.", len(updates), 1)
+	}
+}
+
+func TestWebhookHandlerRejectsUnstoredUpdates(t *testing.T) {
+	updates := make(chan telegram.Update, 1)
+	handler := newWebhookTestHandler(failingUpdateRepository{newMemoryUpdateRepository()}, updates)
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, newWebhookTestRequest(`{"update_id": 42}`))
+
+	// Telegram resends update, which is not acknowledged
+	if recorder.Code != http.StatusInternalServerError {
+		t.Errorf("Invalid status, got: %d, instead of: %d.", recorder.Code, http.StatusInternalServerError)
+	}
+
+	if len(updates) > 0 {
+		t.Errorf("Unstored update must not be queued.")
+	}
+}
+
+func TestWebhookHandlerRejectsRequests(t *testing.T) {
+	requests := map[string]struct {
+		method string
+		secret string
+		body   string
+		status int
+	}{
+		"no secret":      {http.MethodPost, "", `{"update_id": 1}`, http.StatusUnauthorized},
+		"invalid secret": {http.MethodPost, "wrong", `{"update_id": 1}`, http.StatusUnauthorized},
+		"invalid method": {http.MethodGet, "s3cr3t", "", http.StatusMethodNotAllowed},
+		"invalid body":   {http.MethodPost, "s3cr3t", `{"update_id":`, http.StatusBadRequest},
+	}
+
+	for name, target := range requests {
+		updates := make(chan telegram.Update, 1)
+		handler := newWebhookTestHandler(newMemoryUpdateRepository(), updates)
+
+		request := httptest.NewRequest(target.method, "/webhook", strings.NewReader(target.body))
+		if target.secret != "" {
+			request.Header.Set("X-Telegram-Bot-Api-Secret-Token", target.secret)
+		}
+
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, request)
+
+		if recorder.Code != target.status {
+			t.Errorf("Invalid status for: %s, got: %d, instead of: %d.", name, recorder.Code, target.status)
+		}
+
+		if len(updates) > 0 {
+			t.Errorf("Update must not be accepted for: %s.", name)
+		}
+	}
+}
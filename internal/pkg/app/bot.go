@@ -1,37 +1,39 @@
 package app
 
 import (
+	"bot/internal/app/chart"
 	"bot/internal/app/core"
 	"bot/internal/app/database"
 	"bot/internal/app/helpers"
 	"bot/internal/app/logger"
 	"bot/internal/app/marketplace"
+	"bot/internal/app/notification"
 	"bot/internal/app/statemachine"
 	"bot/internal/app/telegram"
+	"context"
 	"crypto/md5"
 	"encoding/hex"
+	"errors"
+	"fmt"
 	"log"
 	"os"
-	"reflect"
+	"os/signal"
 	"strconv"
 	"strings"
+	"sync"
+	"syscall"
 	"time"
+
+	"github.com/jackc/pgx/v5"
 )
 
+// Product which is being added to tracking, its data is kept in conversation.
 type TrackedProduct struct {
-	scrapedAt      time.Time
-	telegramChatId int
-	telegramUserId int
-	marketplace    marketplace.Marketplace
-	url            string
-	title          string
-	thresholdPrice int
-	currentPrice   int
-	outOfStock     bool
+	telegram.ProductDraft
 }
 
 func (p *TrackedProduct) GetScrapedAt() time.Time {
-	return p.scrapedAt
+	return p.ScrapedAt
 }
 
 func (p *TrackedProduct) GetSlug() string {
@@ -39,53 +41,91 @@ func (p *TrackedProduct) GetSlug() string {
 }
 
 func (p *TrackedProduct) GetTelegramChatId() int {
-	return p.telegramChatId
+	return p.TelegramChatId
 }
 
 func (p *TrackedProduct) GetTelegramUserId() int {
-	return p.telegramUserId
+	return p.TelegramUserId
 }
 
-func (p *TrackedProduct) GetUrl() string {
-	return p.url
+func (p *TrackedProduct) GetTelegramUserName() string {
+	return p.TelegramUserName
 }
 
-func (p *TrackedProduct) GetMarketplace() marketplace.Marketplace {
-	return p.marketplace
+func (p *TrackedProduct) GetUrl() string {
+	return p.Url
 }
 
-func (p *TrackedProduct) IsActive() bool {
-	return true
+func (p *TrackedProduct) GetMarketplace() marketplace.Marketplace {
+	return marketplace.Marketplace(p.Marketplace)
 }
 
 func (p *TrackedProduct) GetTitle() string {
-	return p.title
+	return p.Title
 }
 
 func (p *TrackedProduct) GetThresholdPrice() int {
-	return p.thresholdPrice
+	return p.ThresholdPrice
 }
 
 func (p *TrackedProduct) GetCurrentPrice() int {
-	return p.currentPrice
+	return p.CurrentPrice
 }
 
 func (p *TrackedProduct) IsOutOfStock() bool {
-	return p.outOfStock
+	return p.OutOfStock
+}
+
+func (p *TrackedProduct) GetTargetPrice() int {
+	return p.TargetPrice
+}
+
+const (
+	ConversationStorePostgres = "postgres"
+	ConversationStoreMemory   = "memory"
+)
+
+// Names of notification settings in callback data of inline buttons.
+const (
+	settingPriceIncrease   = "increase"
+	settingSoldOut         = "soldout"
+	settingUnchangedDigest = "unchanged"
+	settingDigestMode      = "digest"
+	settingDigestHour      = "hour"
+)
+
+type TelegramBotAppConfig struct {
+	Token                            string
+	ScraperTimeoutInSeconds          int
+	ScraperBrowserPagesLimit         int
+	WatcherIntervalInMinutes         int
+	WatcherConcurrency               int
+	WatcherConcurrencyPerMarketplace int
+	WatcherConcurrencyByMarketplace  map[marketplace.Marketplace]int
+	WebhookUrl                       string
+	WebhookSecret                    string
+	WebhookListenAddress             string
+	ConversationStore                string
 }
 
 type TelegramBotApp struct {
-	bot                      telegram.Bot
-	conversations            map[string]*telegram.Conversation
-	marketplaceService       marketplace.Service
-	logger                   logger.LoggerInterface
-	timeLocation             *time.Location
-	scraperTimeoutInSeconds  int
-	watcherIntervalInMinutes int
+	bot                 telegram.Bot
+	outboxBot           telegram.Bot
+	conversationStore   telegram.ConversationStore
+	conversationManager *telegram.ConversationManager
+	updateJournal       telegram.UpdateJournal
+	marketplaceService  marketplace.Service
+	scrapeJobRepository marketplace.ScrapeJobRepository
+	scrapeJobQueue      *marketplace.ScrapeJobQueue
+	scrapeLoaders       *sync.Map
+	notificationService notification.Service
+	logger              logger.LoggerInterface
+	timeLocation        *time.Location
+	config              TelegramBotAppConfig
 }
 
-func NewTelegramBotApp(token string, logger logger.LoggerInterface, scraperTimeoutInSeconds int, watcherIntervalInMinutes int) TelegramBotApp {
-	bot, err := telegram.NewBot(token, logger)
+func NewTelegramBotApp(config TelegramBotAppConfig, logger logger.LoggerInterface) TelegramBotApp {
+	bot, err := telegram.NewBot(config.Token, logger)
 	if err != nil {
 		log.Fatalln(err)
 	}
@@ -96,60 +136,214 @@ func NewTelegramBotApp(token string, logger logger.LoggerInterface, scraperTimeo
 	}
 
 	repository := marketplace.NewPostgresRepository(db, logger)
+	historyRepository := marketplace.NewPostgresPriceHistoryRepository(db, logger)
+	alertRuleRepository := marketplace.NewPostgresAlertRuleRepository(db, logger)
+	sharedWatchlistRepository := marketplace.NewPostgresSharedWatchlistRepository(db, logger)
+	updateRepository := telegram.NewPostgresUpdateRepository(db, logger)
+	scrapeJobRepository := marketplace.NewPostgresScrapeJobRepository(db, logger)
+	settingsRepository := notification.NewPostgresSettingsRepository(db, logger)
+	notificationRepository := notification.NewPostgresNotificationRepository(db, logger)
 
 	timezone := os.Getenv("TIMEZONE")
 	timeLocation, _ := time.LoadLocation(timezone)
 
 	return TelegramBotApp{
-		bot:                      bot,
-		conversations:            make(map[string]*telegram.Conversation),
-		marketplaceService:       marketplace.NewService(&repository, logger),
-		logger:                   logger,
-		timeLocation:             timeLocation,
-		scraperTimeoutInSeconds:  scraperTimeoutInSeconds,
-		watcherIntervalInMinutes: watcherIntervalInMinutes,
+		bot:                 bot,
+		outboxBot:           bot.WithoutRetries(),
+		conversationStore:   newConversationStore(config.ConversationStore, db, logger),
+		conversationManager: telegram.NewConversationManager(),
+		updateJournal:       telegram.NewUpdateJournal(&updateRepository, logger),
+		marketplaceService:  marketplace.NewService(&repository, &historyRepository, &alertRuleRepository, &sharedWatchlistRepository, logger),
+		scrapeJobRepository: &scrapeJobRepository,
+		scrapeLoaders:       &sync.Map{},
+		notificationService: notification.NewService(&settingsRepository, &notificationRepository, logger),
+		logger:              logger,
+		timeLocation:        timeLocation,
+		config:              config,
 	}
 }
 
 func (app *TelegramBotApp) Run() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	browserPool := marketplace.NewBrowserPool(ctx, app.logger, app.config.WatcherConcurrency, app.config.ScraperBrowserPagesLimit)
+	scraper := marketplace.NewScraper(browserPool, app.logger, app.config.ScraperTimeoutInSeconds)
+
+	pool := marketplace.NewWorkerPool(
+		ctx,
+		scraper,
+		app.logger,
+		app.config.WatcherConcurrency,
+		app.config.WatcherConcurrencyPerMarketplace,
+		app.config.WatcherConcurrencyByMarketplace,
+	)
+
 	app.collectGarbage()
-	app.watchTrackedProducts()
+	app.watchTrackedProducts(ctx, pool)
+	app.sendUnchangedDigests(ctx)
+	app.sendDigests(ctx)
+	app.dispatchNotifications(ctx)
 
-	app.logger.Println(helpers.ConcatStrings("I'm the @", app.bot.WhoAmI.UserName, " now"))
+	// on-demand scrapes share the pool with watcher, but are taken first
+	app.scrapeJobQueue = marketplace.NewScrapeJobQueue(app.scrapeJobRepository, pool, app.logger)
+	go app.scrapeJobQueue.Run(ctx, app.onScrapeJobDone)
 
-	defaultOffsetId := 0
+	app.logger.Println(helpers.ConcatStrings("I'm the @", app.bot.WhoAmI.UserName, " now"))
 
-	app.bot.ListenForUpdates(func(update telegram.Update) {
-		var message telegram.Message
+	listenerDone := make(chan struct{})
 
-		if update.CallbackQuery.Id != "" {
-			message = update.CallbackQuery.Message
+	go func() {
+		defer close(listenerDone)
 
-			// mimic a message from user, not from bot
-			message.From = update.CallbackQuery.From
-			message.Text = update.CallbackQuery.Data
-		} else {
-			message = update.Message
+		if err := app.listenForUpdates(ctx); err != nil {
+			app.logger.Println("ERROR! Unable to listen for updates:", err)
+			stop()
 		}
+	}()
+
+	<-ctx.Done()
+
+	app.logger.Println("Shutting down, waiting for scrapers to stop...")
+
+	// no more updates are dispatched after listener is stopped (and webhook is deleted)
+	<-listenerDone
+
+	pool.Wait()
+	app.conversationManager.Wait()
+	browserPool.Close()
+
+	app.logger.Println("Bye")
+}
+
+// Create conversation store of the given type ("postgres" by default).
+func newConversationStore(storeType string, db *database.Postgres, logger logger.LoggerInterface) telegram.ConversationStore {
+	if storeType == ConversationStoreMemory {
+		return telegram.NewMemoryConversationStore()
+	}
+
+	store := telegram.NewPostgresConversationStore(db, logger, marketplace.NewFsm)
+
+	return &store
+}
 
-		hash := app.calculateConversationHash(message)
+// Listen for incoming updates (via webhook if it's configured, otherwise via polling) and process them.
+// Each update is stored before processing, so updates which were not processed before restart are processed first.
+func (app *TelegramBotApp) listenForUpdates(ctx context.Context) error {
+	for _, update := range app.updateJournal.FindUnprocessed() {
+		app.dispatchUpdate(update)
+	}
+
+	// webhook stores updates itself before they are acknowledged
+	if app.isWebhookMode() {
+		return app.bot.ListenForWebhook(ctx, telegram.WebhookConfig{
+			Url:           app.config.WebhookUrl,
+			Secret:        app.config.WebhookSecret,
+			ListenAddress: app.config.WebhookListenAddress,
+		}, &app.updateJournal, app.dispatchUpdate)
+	}
+
+	// updates can't be polled while webhook is set
+	if err := app.bot.DeleteWebhook(); err != nil {
+		app.logger.Println("Unable to delete webhook:", err)
+	}
+
+	app.bot.ListenForUpdates(ctx, app.receiveUpdate, app.updateJournal.GetOffset())
+
+	return nil
+}
 
-		conversation, exists := app.conversations[hash]
+// Check if updates are received via webhook.
+func (app *TelegramBotApp) isWebhookMode() bool {
+	return app.config.WebhookUrl != ""
+}
+
+// Store incoming update and pass it for processing, duplicates are skipped.
+func (app *TelegramBotApp) receiveUpdate(update telegram.Update) {
+	if !app.updateJournal.Receive(update) {
+		return
+	}
 
-		if !exists {
-			app.conversations[hash] = telegram.NewConversation(message.Chat.Id, message.From)
+	app.dispatchUpdate(update)
+}
+
+// Process update in background, updates of the same conversation are processed one by one,
+// so slow actions (e.g. scraping) of one user don't block the others.
+func (app *TelegramBotApp) dispatchUpdate(update telegram.Update) {
+	hash := app.calculateConversationHash(app.getUpdateMessage(update))
+
+	app.conversationManager.Dispatch(hash, func() {
+		app.processUpdate(update)
+		app.updateJournal.MarkProcessed(update)
+	})
+}
 
-			conversation = app.conversations[hash]
+// Get message of update, callback query is treated as a message from user.
+func (app *TelegramBotApp) getUpdateMessage(update telegram.Update) telegram.Message {
+	if update.MyChatMember.Chat.Id != 0 {
+		return telegram.Message{
+			Chat: update.MyChatMember.Chat,
+			From: update.MyChatMember.From,
 		}
+	}
+
+	if update.CallbackQuery.Id == "" {
+		return update.Message
+	}
+
+	message := update.CallbackQuery.Message
+
+	// mimic a message from user, not from bot
+	message.From = update.CallbackQuery.From
+	message.Text = update.CallbackQuery.Data
+
+	return message
+}
+
+// Process incoming update.
+func (app *TelegramBotApp) processUpdate(update telegram.Update) {
+	if update.MyChatMember.Chat.Id != 0 {
+		app.processChatMemberUpdate(update.MyChatMember)
+		return
+	}
+
+	message := app.getUpdateMessage(update)
+	hash := app.calculateConversationHash(message)
+
+	// e.g. "/listproducts@bot" in group
+	text, isForMe := telegram.NormalizeCommand(message.Text, app.bot.WhoAmI.UserName)
+	if !isForMe {
+		return
+	}
+
+	message.Text = text
 
-		conversation.LastMessage = message
-		conversation.LastCallbackQueryId = update.CallbackQuery.Id
+	conversation, exists := app.conversationStore.Find(hash)
 
-		app.processConversation(conversation)
-	}, defaultOffsetId)
+	if !exists {
+		conversation = telegram.NewConversation(message.Chat.Id, message.From)
+	}
+
+	conversation.LastMessage = message
+	conversation.LastCallbackQueryId = update.CallbackQuery.Id
+
+	app.processConversation(conversation)
+
+	if err := app.conversationStore.Save(hash, conversation); err != nil {
+		app.logger.Println("ERROR! Unable to save conversation:", err)
+	}
+}
+
+// Pause products of chat when the bot is blocked (or removed from group), resume them when it's back.
+func (app *TelegramBotApp) processChatMemberUpdate(update telegram.ChatMemberUpdated) {
+	if update.NewChatMember.HasLeft() {
+		app.marketplaceService.PauseForChat(update.Chat.Id)
+	} else if update.OldChatMember.HasLeft() {
+		app.marketplaceService.ResumeForChat(update.Chat.Id)
+	}
 }
 
-// Collect garbage (delete hanged conversations).
+// Collect garbage (delete hanged conversations and old updates).
 func (app *TelegramBotApp) collectGarbage() {
 	const intervalInMinutes = 10
 
@@ -157,92 +351,386 @@ func (app *TelegramBotApp) collectGarbage() {
 
 	go func() {
 		for range gcTicker.C {
-			currentTimestamp := int(time.Now().Unix())
+			updatedBefore := time.Now().Add(-time.Duration(intervalInMinutes) * time.Minute)
 
-			for hash, conversation := range app.conversations {
-				conversationTimestampHanged := conversation.LastMessage.Date + intervalInMinutes*int(time.Minute)
-
-				if conversationTimestampHanged > currentTimestamp {
-					continue
-				}
-
-				conversation.Reset()
-				delete(app.conversations, hash)
+			// conversation waits for queued scrape as long as it takes
+			if err := app.conversationStore.DeleteInactive(updatedBefore, marketplace.StateScraping); err != nil {
+				app.logger.Println("Unable to delete hanged conversations:", err)
 			}
+
+			app.updateJournal.Cleanup()
 		}
 	}()
 }
 
 // Scrape tracked products in background.
-func (app *TelegramBotApp) watchTrackedProducts() {
-	watcher := marketplace.NewWatcher(app.marketplaceService, app.logger, app.scraperTimeoutInSeconds, app.watcherIntervalInMinutes)
-
-	resultChannel := make(chan marketplace.WatcherResult)
+func (app *TelegramBotApp) watchTrackedProducts(ctx context.Context, pool *marketplace.WorkerPool) {
+	watcher := marketplace.NewWatcher(app.marketplaceService, &app.notificationService, pool, app.logger, app.config.WatcherIntervalInMinutes)
 
 	go func() {
 		for {
-			err := watcher.Run(resultChannel)
-			if err != nil {
+			err := watcher.Run(ctx)
+			if err != nil && ctx.Err() == nil {
 				app.logger.Println("Error while watching:", err)
 			}
 
-			time.Sleep(time.Duration(app.watcherIntervalInMinutes) * time.Minute)
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(time.Duration(app.config.WatcherIntervalInMinutes) * time.Minute):
+			}
 		}
 	}()
+}
 
-	go func() {
-		for result := range resultChannel {
-			request := telegram.SendMessageRequest{
-				LinkPreviewOptions: telegram.LinkPreviewOptions{
-					PreferSmallMedia: true,
-					Url:              result.Original.GetUrl(),
-				},
-				ReplyMarkup: telegram.InlineKeyboardMarkup{
-					Keyboard: [][]telegram.InlineKeyboardButton{
-						{
-							{
-								Text: "Перейти к товару",
-								Url:  result.Original.GetUrl(),
-							},
-						},
+// Send notifications from outbox (except the ones which wait for digest).
+func (app *TelegramBotApp) dispatchNotifications(ctx context.Context) {
+	dispatcher := notification.NewDispatcher(&app.notificationService, app.logger)
+
+	go dispatcher.Run(ctx, app.sendNotification)
+}
+
+// Send notification about product change.
+func (app *TelegramBotApp) sendNotification(n notification.Notification) error {
+	request := telegram.SendMessageRequest{
+		LinkPreviewOptions: telegram.LinkPreviewOptions{
+			PreferSmallMedia: true,
+			Url:              n.Url,
+		},
+		ReplyMarkup: telegram.InlineKeyboardMarkup{
+			Keyboard: [][]telegram.InlineKeyboardButton{
+				{
+					{
+						Text: "Перейти к товару",
+						Url:  n.Url,
 					},
 				},
-			}
+			},
+		},
+	}
+
+	if len(n.Alerts) > 0 {
+		request.Text = app.buildAlertMessage(n)
+	} else {
+		request.Text = app.buildOptInEventMessage(n)
+	}
+
+	// notification is seen by all members of group, so tell whose product it is
+	if telegram.IsGroupChatId(n.TelegramChatId) {
+		// name is saved along with product, products added before that have no name
+		user := telegram.User{Id: n.TelegramUserId, FirstName: n.TelegramUserName}
+		if user.FirstName == "" {
+			user.FirstName = "участник чата"
+		}
+
+		request.Text = helpers.ConcatStrings(request.Text, "\n\n", "Товар добавил(а) ", user.GetMention())
+	}
+
+	// failed notification is retried by dispatcher, so don't block it with retries of request
+	_, err := app.outboxBot.SendMessage(n.TelegramChatId, request)
+
+	// user has blocked the bot, so don't scrape the products until user is back
+	if telegram.IsChatUnavailable(err) {
+		app.marketplaceService.PauseForChat(n.TelegramChatId)
+		return fmt.Errorf("%w: %s", notification.ErrUndeliverable, err)
+	}
+
+	// bot can't write to group (e.g. it's restricted by administrators), but products are still tracked
+	if errors.Is(err, telegram.ErrNotEnoughRights) {
+		return fmt.Errorf("%w: %s", notification.ErrUndeliverable, err)
+	}
+
+	return err
+}
 
-			if result.Scraped.IsOutOfStock() || (result.Scraped.GetCurrentPrice() == 0) {
+// Build notification message, which explains why it's sent.
+func (app *TelegramBotApp) buildAlertMessage(n notification.Notification) string {
+	product := n.GetProduct()
+
+	headline := helpers.ConcatStrings("Снизилась цена на товар! ", string(telegram.EmojiMoneyMouthFace))
+
+	for _, ruleType := range []marketplace.AlertRuleType{
+		marketplace.AlertRuleLowestInDays,
+		marketplace.AlertRuleTargetPrice,
+		marketplace.AlertRuleBackInStock,
+	} {
+		for _, rule := range n.Alerts {
+			if rule.Type != ruleType {
 				continue
 			}
 
-			if result.Original.IsOutOfStock() && !result.Scraped.IsOutOfStock() {
-				// in stock again
-				request.Text = helpers.ConcatStrings(
-					"Товар снова в продаже! ", string(telegram.EmojiParty), "\n\n",
-					"<b><a href=\"", result.Original.GetUrl(), "\">", result.Original.GetTitle(), "</a></b> (", marketplace.GetMarketplaceName(result.Original), ")\n\n",
-					"Текущая цена: <b>", helpers.CurrencyFormat(helpers.CurrencyToMajor(result.Scraped.GetCurrentPrice())), "</b>",
-				)
-			} else if result.Original.GetThresholdPrice() > result.Scraped.GetCurrentPrice() {
-				// price is now lower
-				request.Text = helpers.ConcatStrings(
-					"Снизилась цена на товар! ", string(telegram.EmojiMoneyMouthFace), "\n\n",
-					"<b><a href=\"", result.Original.GetUrl(), "\">", result.Original.GetTitle(), "</a></b> (", marketplace.GetMarketplaceName(result.Original), ")\n\n",
-					"Новая цена: <b>", helpers.CurrencyFormat(helpers.CurrencyToMajor(result.Scraped.GetCurrentPrice())), "</b>\n",
-					"Старая цена: <s>", helpers.CurrencyFormat(helpers.CurrencyToMajor(result.Original.GetThresholdPrice())), "</s>",
-				)
+			switch rule.Type {
+			case marketplace.AlertRuleLowestInDays:
+				headline = helpers.ConcatStrings("Самая низкая цена за ", strconv.Itoa(rule.Value), " дн.! ", string(telegram.EmojiMoneyMouthFace))
+			case marketplace.AlertRuleTargetPrice:
+				headline = helpers.ConcatStrings("Цена достигла целевой! ", string(telegram.EmojiMoneyMouthFace))
+			case marketplace.AlertRuleBackInStock:
+				headline = helpers.ConcatStrings("Товар снова в продаже! ", string(telegram.EmojiParty))
 			}
+		}
+	}
 
-			if request.Text == "" {
-				continue
+	text := helpers.ConcatStrings(
+		headline, "\n\n",
+		"<b><a href=\"", n.Url, "\">", n.Title, "</a></b> (", marketplace.GetMarketplaceName(&product), ")\n\n",
+		"Новая цена: <b>", helpers.CurrencyFormat(helpers.CurrencyToMajor(n.NewPrice)), "</b>",
+	)
+
+	if n.OldPrice > n.NewPrice {
+		text = helpers.ConcatStrings(text, "\n", "Старая цена: <s>", helpers.CurrencyFormat(helpers.CurrencyToMajor(n.OldPrice)), "</s>")
+	}
+
+	if n.TargetPrice > 0 {
+		text = helpers.ConcatStrings(text, "\n", "Целевая цена: ", helpers.CurrencyFormat(helpers.CurrencyToMajor(n.TargetPrice)))
+	}
+
+	text = helpers.ConcatStrings(text, "\n\n", "<i>Сработало правило:")
+
+	for _, rule := range n.Alerts {
+		text = helpers.ConcatStrings(text, "\n", "• ", app.describeAlertRule(rule, &product))
+	}
+
+	return helpers.ConcatStrings(text, "</i>")
+}
+
+// Build notification message of event which user has opted in for (e.g. price increase).
+func (app *TelegramBotApp) buildOptInEventMessage(n notification.Notification) string {
+	product := n.GetProduct()
+	text := ""
+
+	switch n.Event {
+	case notification.EventPriceIncrease:
+		text = helpers.ConcatStrings(
+			"Цена на товар выросла ", string(telegram.EmojiChartIncreasing), "\n\n",
+			"<b><a href=\"", n.Url, "\">", n.Title, "</a></b> (", marketplace.GetMarketplaceName(&product), ")\n\n",
+			"Новая цена: <b>", helpers.CurrencyFormat(helpers.CurrencyToMajor(n.NewPrice)), "</b>\n",
+			"Старая цена: ", helpers.CurrencyFormat(helpers.CurrencyToMajor(n.OldPrice)),
+		)
+	case notification.EventSoldOut:
+		text = helpers.ConcatStrings(
+			"Товар закончился ", string(telegram.EmojiWhiteFrowningFace), "\n\n",
+			"<b><a href=\"", n.Url, "\">", n.Title, "</a></b> (", marketplace.GetMarketplaceName(&product), ")\n\n",
+			"Последняя цена: ", helpers.CurrencyFormat(helpers.CurrencyToMajor(n.OldPrice)),
+		)
+	}
+
+	return helpers.ConcatStrings(text, "\n\n", "<i>Уведомления настраиваются командой ", telegram.CommandSettings, "</i>")
+}
+
+// Send digests of products which price hasn't changed for a while (to users who have opted in), checked once an hour.
+func (app *TelegramBotApp) sendUnchangedDigests(ctx context.Context) {
+	const intervalInMinutes = 60
+
+	go func() {
+		for {
+			for _, settings := range app.notificationService.FindUnchangedDigestDue() {
+				if ctx.Err() != nil {
+					return
+				}
+
+				app.sendUnchangedDigest(settings)
 			}
 
-			_, err := app.bot.SendMessage(result.Original.GetTelegramChatId(), request)
-			if err != nil {
-				app.logger.Println("ERROR! Unable to send listing message:", err)
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(time.Duration(intervalInMinutes) * time.Minute):
+			}
+		}
+	}()
+}
+
+// Send digest of products which price hasn't changed for the number of days from user settings.
+func (app *TelegramBotApp) sendUnchangedDigest(settings notification.UserSettings) {
+	const maxProducts = 20
+
+	// the whole list of chat, if it's shared
+	ownerId := app.marketplaceService.GetWatchlistOwnerId(settings.TelegramChatId, settings.TelegramUserId)
+
+	products := app.marketplaceService.FindUnchangedForUser(settings.TelegramChatId, ownerId, settings.UnchangedDigestDays)
+
+	// nothing is marked as sent, so it's checked again later
+	if len(products) == 0 {
+		return
+	}
+
+	text := helpers.ConcatStrings(
+		"Цена не менялась больше ", strconv.Itoa(settings.UnchangedDigestDays), " дн. ", string(telegram.EmojiHourglass), "\n",
+	)
+
+	for i, product := range products {
+		if i == maxProducts {
+			text = helpers.ConcatStrings(text, "\n", "...и ещё ", strconv.Itoa(len(products)-maxProducts), ", весь список: ", telegram.CommandListProducts)
+			break
+		}
+
+		text = helpers.ConcatStrings(
+			text, "\n",
+			"• <a href=\"", product.Url, "\">", product.Title, "</a> (", marketplace.GetMarketplaceName(&product), "): ",
+			helpers.CurrencyFormat(helpers.CurrencyToMajor(product.CurrentPrice)),
+		)
+	}
+
+	text = helpers.ConcatStrings(text, "\n\n", "<i>Уведомления настраиваются командой ", telegram.CommandSettings, "</i>")
+
+	_, err := app.bot.SendMessage(settings.TelegramChatId, telegram.SendMessageRequest{
+		Text: text,
+		LinkPreviewOptions: telegram.LinkPreviewOptions{
+			IsDisabled: true,
+		},
+	})
+
+	if telegram.IsChatUnavailable(err) {
+		app.marketplaceService.PauseForChat(settings.TelegramChatId)
+	} else if err != nil {
+		app.logger.Println("ERROR! Unable to send unchanged prices digest:", err)
+		return
+	}
+
+	if err := app.notificationService.MarkUnchangedDigestSent(settings); err != nil {
+		app.logger.Println("ERROR! Unable to mark unchanged prices digest as sent:", err)
+	}
+}
+
+// Send digests of accumulated notifications to users at the time they have chosen.
+func (app *TelegramBotApp) sendDigests(ctx context.Context) {
+	const intervalInMinutes = 5
+
+	go func() {
+		for {
+			for _, settings := range app.notificationService.FindDigestsDue(app.timeLocation) {
+				if ctx.Err() != nil {
+					return
+				}
+
+				app.sendDigest(settings)
+			}
+
+			select {
+			case <-ctx.Done():
 				return
+			case <-time.After(time.Duration(intervalInMinutes) * time.Minute):
 			}
 		}
 	}()
 }
 
+// Send accumulated notifications of user as one message (or several ones, if it's too long).
+func (app *TelegramBotApp) sendDigest(settings notification.UserSettings) {
+	notifications := app.notificationService.FindPendingForUser(settings.TelegramChatId, settings.TelegramUserId)
+
+	if len(notifications) > 0 {
+		header := "Сводка изменений цен за день"
+		if settings.DigestMode == notification.DigestModeWeekly {
+			header = "Сводка изменений цен за неделю"
+		}
+
+		var entries []string
+
+		for _, n := range notifications {
+			entries = append(entries, app.buildDigestEntry(n))
+		}
+
+		pages := notification.SplitDigestPages(helpers.ConcatStrings("<b>", header, "</b> ", string(telegram.EmojiBell)), entries, notification.MessageMaxLength)
+
+		for _, page := range pages {
+			_, err := app.bot.SendMessage(settings.TelegramChatId, telegram.SendMessageRequest{
+				Text: page.Text,
+				LinkPreviewOptions: telegram.LinkPreviewOptions{
+					IsDisabled: true,
+				},
+			})
+
+			// undelivered notifications are kept until user is back
+			if telegram.IsChatUnavailable(err) {
+				app.marketplaceService.PauseForChat(settings.TelegramChatId)
+				break
+			}
+
+			// the rest is sent next time
+			if err != nil {
+				app.logger.Println("ERROR! Unable to send digest:", err)
+				return
+			}
+
+			// delivered pages are not sent again, even if the next page fails
+			if err := app.notificationService.MarkSent(notifications[:page.EntriesCount]); err != nil {
+				app.logger.Println("ERROR! Unable to mark notifications as sent:", err)
+				return
+			}
+
+			notifications = notifications[page.EntriesCount:]
+		}
+	}
+
+	if err := app.notificationService.MarkDigestSent(settings); err != nil {
+		app.logger.Println("ERROR! Unable to mark digest as sent:", err)
+	}
+}
+
+// Build digest line of notification: product, price change and reason.
+func (app *TelegramBotApp) buildDigestEntry(n notification.Notification) string {
+	product := n.GetProduct()
+
+	text := helpers.ConcatStrings(
+		"\n", "• <a href=\"", product.Url, "\">", product.Title, "</a> (", marketplace.GetMarketplaceName(&product), ")\n",
+	)
+
+	if n.Event == notification.EventSoldOut {
+		return helpers.ConcatStrings(text, "товар закончился ", string(telegram.EmojiWhiteFrowningFace))
+	}
+
+	if n.OldPrice > 0 && n.OldPrice != n.NewPrice {
+		text = helpers.ConcatStrings(text, "<s>", helpers.CurrencyFormat(helpers.CurrencyToMajor(n.OldPrice)), "</s> → ")
+	}
+
+	text = helpers.ConcatStrings(text, "<b>", helpers.CurrencyFormat(helpers.CurrencyToMajor(n.NewPrice)), "</b>")
+
+	if n.Event == notification.EventPriceIncrease {
+		return helpers.ConcatStrings(text, ", цена выросла")
+	}
+
+	var reasons []string
+
+	for _, rule := range n.Alerts {
+		reasons = append(reasons, app.describeAlertRule(rule, &product))
+	}
+
+	if len(reasons) > 0 {
+		text = helpers.ConcatStrings(text, ", <i>", strings.Join(reasons, "; "), "</i>")
+	}
+
+	return text
+}
+
+// Get human readable description of notification rule.
+func (app *TelegramBotApp) describeAlertRule(rule marketplace.AlertRule, product marketplace.ProductDto) string {
+	switch rule.Type {
+	case marketplace.AlertRuleDropPercent:
+		percent := strconv.FormatFloat(float64(rule.Value)/100, 'f', -1, 64)
+
+		return helpers.ConcatStrings("снижение цены на ", percent, "% и больше")
+	case marketplace.AlertRuleDropAmount:
+		if rule.Value <= 1 {
+			return "любое снижение цены"
+		}
+
+		return helpers.ConcatStrings("снижение цены на ", helpers.CurrencyFormat(helpers.CurrencyToMajor(rule.Value)), " и больше")
+	case marketplace.AlertRuleTargetPrice:
+		if product.GetTargetPrice() == 0 {
+			return "цена не выше целевой (не задана)"
+		}
+
+		return helpers.ConcatStrings("цена не выше целевой (", helpers.CurrencyFormat(helpers.CurrencyToMajor(product.GetTargetPrice())), ")")
+	case marketplace.AlertRuleLowestInDays:
+		return helpers.ConcatStrings("самая низкая цена за ", strconv.Itoa(rule.Value), " дн.")
+	case marketplace.AlertRuleBackInStock:
+		return "снова в продаже"
+	}
+
+	return string(rule.Type)
+}
+
 // Calculate conversation hash based on chat and user ids.
 func (app *TelegramBotApp) calculateConversationHash(message telegram.Message) string {
 	data := helpers.ConcatStrings(strconv.Itoa(message.Chat.Id), "_", strconv.Itoa(message.From.Id))
@@ -282,32 +770,90 @@ func (app *TelegramBotApp) processConversation(conversation *telegram.Conversati
 		return
 	}
 
-	// "track product" command
-	if telegram.IsTrackProductCommand(conversation.LastMessage.Text) {
-		conversation.StateMachine = marketplace.NewFsm()
-
-		_, err := conversation.StateMachine.TriggerEvent(marketplace.EventAskForUrl)
-		if err != nil {
-			app.logErrorAndSendMessage(
-				conversation,
-				err,
-				helpers.ConcatStrings("Unable to trigger state machine \"", string(marketplace.EventAskForUrl), "\" event"),
-				"Не могу перейти к запросу ссылки",
-			)
+	// inline button of listed product
+	if conversation.LastCallbackQueryId != "" {
+		if data, err := telegram.ParseCallbackData(conversation.LastMessage.Text); err == nil {
+			app.processCallbackAction(conversation, data)
 			return
 		}
 	}
 
-	// "list products" command
-	if telegram.IsListProductsCommand(conversation.LastMessage.Text) {
-		conversation.StateMachine = marketplace.NewFsm()
+	// "settings" command and its inline buttons
+	if telegram.IsSettingsCommand(conversation.LastMessage.Text) {
+		app.showNotificationSettings(conversation)
+		return
+	}
 
-		_, err := conversation.StateMachine.TriggerEvent(marketplace.EventList)
-		if err != nil {
-			app.logErrorAndSendMessage(
-				conversation,
-				err,
-				helpers.ConcatStrings("Unable to trigger state machine \"", string(marketplace.EventList), "\" event"),
+	if telegram.IsToggleSettingCommand(conversation.LastMessage.Text) {
+		app.toggleNotificationSetting(conversation)
+		return
+	}
+
+	if telegram.IsTimezoneCommand(conversation.LastMessage.Text) {
+		app.setUserTimezone(conversation)
+		return
+	}
+
+	// "shared list" command
+	if telegram.IsSharedListCommand(conversation.LastMessage.Text) {
+		app.toggleSharedWatchlist(conversation)
+		return
+	}
+
+	// "price history" command
+	if telegram.IsPriceHistoryCommand(conversation.LastMessage.Text) {
+		app.showPriceHistory(conversation)
+		return
+	}
+
+	// "pause product" and "resume product" commands
+	if telegram.IsPauseProductCommand(conversation.LastMessage.Text) {
+		app.setMarketplaceProductActive(conversation, false)
+		return
+	}
+
+	if telegram.IsResumeProductCommand(conversation.LastMessage.Text) {
+		app.setMarketplaceProductActive(conversation, true)
+		return
+	}
+
+	// "pause all" and "resume all" commands
+	if telegram.IsPauseAllCommand(conversation.LastMessage.Text) {
+		app.setAllMarketplaceProductsActive(conversation, false)
+		return
+	}
+
+	if telegram.IsResumeAllCommand(conversation.LastMessage.Text) {
+		app.setAllMarketplaceProductsActive(conversation, true)
+		return
+	}
+
+	// "track product" command
+	if telegram.IsTrackProductCommand(conversation.LastMessage.Text) {
+		conversation.StateMachine = marketplace.NewFsm()
+
+		_, err := conversation.StateMachine.TriggerEvent(marketplace.EventAskForUrl)
+		if err != nil {
+			app.logErrorAndSendMessage(
+				conversation,
+				err,
+				helpers.ConcatStrings("Unable to trigger state machine \"", string(marketplace.EventAskForUrl), "\" event"),
+				"Не могу перейти к запросу ссылки",
+			)
+			return
+		}
+	}
+
+	// "list products" command
+	if telegram.IsListProductsCommand(conversation.LastMessage.Text) {
+		conversation.StateMachine = marketplace.NewFsm()
+
+		_, err := conversation.StateMachine.TriggerEvent(marketplace.EventList)
+		if err != nil {
+			app.logErrorAndSendMessage(
+				conversation,
+				err,
+				helpers.ConcatStrings("Unable to trigger state machine \"", string(marketplace.EventList), "\" event"),
 				"Не могу перейти к отображению списка",
 			)
 			return
@@ -333,12 +879,51 @@ func (app *TelegramBotApp) processConversation(conversation *telegram.Conversati
 		}
 	}
 
+	// "alert rules" command
+	if telegram.IsAlertRulesCommand(conversation.LastMessage.Text) {
+		conversation.StateMachine = marketplace.NewFsm()
+
+		_, err := conversation.StateMachine.TriggerEvent(marketplace.EventEditAlertRules)
+		if err != nil {
+			app.logErrorAndSendMessage(
+				conversation,
+				err,
+				helpers.ConcatStrings("Unable to trigger state machine \"", string(marketplace.EventEditAlertRules), "\" event"),
+				"Не могу перейти к изменению правил",
+			)
+			return
+		}
+	}
+
+	// "set target price" command
+	if telegram.IsSetPriceCommand(conversation.LastMessage.Text) {
+		if !conversation.StateMachine.IsInitialized() {
+			conversation.StateMachine = marketplace.NewFsm()
+		}
+
+		_, err := conversation.StateMachine.TriggerEvent(marketplace.EventEditTargetPrice)
+		if err != nil {
+			app.logErrorAndSendMessage(
+				conversation,
+				err,
+				helpers.ConcatStrings("Unable to trigger state machine \"", string(marketplace.EventEditTargetPrice), "\" event"),
+				"Не могу перейти к изменению цены",
+			)
+			return
+		}
+	}
+
 	// check state machine after receiving a command
 	if conversation.StateMachine.GetCurrentState() != statemachine.StateIdle {
 		app.processStateMachine(conversation)
 		return
 	}
 
+	// other messages of group members are not addressed to the bot
+	if conversation.LastMessage.Chat.IsGroup() && !strings.HasPrefix(conversation.LastMessage.Text, "/") {
+		return
+	}
+
 	// unknown command
 	app.bot.SendMessage(conversation.ChatId, telegram.SendMessageRequest{
 		ReplyToMessageId: conversation.LastMessage.MessageId,
@@ -348,15 +933,16 @@ func (app *TelegramBotApp) processConversation(conversation *telegram.Conversati
 
 // Process conversation's state machine.
 func (app *TelegramBotApp) processStateMachine(conversation *telegram.Conversation) {
-	if !app.isTrackedProductContext(conversation) && conversation.StateMachine.IsInOneOfStates([]statemachine.State{
+	if conversation.Data.Product == nil && conversation.StateMachine.IsInOneOfStates([]statemachine.State{
 		marketplace.StateAskingForUrl,
 		marketplace.StateWaitingForUrl,
 		marketplace.StateScraping,
 	}) {
-		conversation.StoreContext(telegram.ConversationCtxProduct, TrackedProduct{
-			telegramChatId: conversation.ChatId,
-			telegramUserId: conversation.User.Id,
-		})
+		conversation.Data.Product = &telegram.ProductDraft{
+			TelegramChatId:   conversation.ChatId,
+			TelegramUserId:   conversation.User.Id,
+			TelegramUserName: conversation.User.GetName(),
+		}
 	}
 
 	switch conversation.StateMachine.GetCurrentState() {
@@ -365,24 +951,50 @@ func (app *TelegramBotApp) processStateMachine(conversation *telegram.Conversati
 	case marketplace.StateWaitingForUrl:
 		app.waitForMarketplaceUrl(conversation)
 	case marketplace.StateScraping:
-		app.scrapeMarketplaceUrl(conversation)
+		app.bot.SendMessage(conversation.ChatId, telegram.SendMessageRequest{
+			ReplyToMessageId: conversation.LastMessage.MessageId,
+			Text:             "Всё ещё ищу, подожди немного",
+		})
 	case marketplace.StateListing:
 		app.showMarketplaceListing(conversation)
 	case marketplace.StateDeleting:
 		app.confirmMarketplaceProductDelete(conversation)
+	case marketplace.StateAskingForTargetPrice:
+		app.askForTargetPrice(conversation)
+	case marketplace.StateWaitingForTargetPrice:
+		app.waitForTargetPrice(conversation)
+	case marketplace.StateEditingTargetPrice:
+		app.editTargetPrice(conversation)
+	case marketplace.StateEditingAlertRules:
+		app.editAlertRules(conversation)
+	case marketplace.StateWaitingForAlertRules:
+		app.waitForAlertRules(conversation)
 	}
 }
 
 // Send message to ask for marketplace URL.
 func (app *TelegramBotApp) askForMarketplaceUrl(conversation *telegram.Conversation) {
-	app.bot.SendMessage(conversation.ChatId, telegram.SendMessageRequest{
+	request := telegram.SendMessageRequest{
 		ReplyToMessageId: conversation.LastMessage.MessageId,
 		Text:             "Отправь мне ссылку на маркетплейс",
-	})
+	}
+
+	app.forceReply(conversation, &request)
+	app.bot.SendMessage(conversation.ChatId, request)
 
 	conversation.StateMachine.TriggerEvent(marketplace.EventWaitForUrl)
 }
 
+// Ask user to reply to the message, since in group with privacy mode the bot gets only replies to its messages.
+func (app *TelegramBotApp) forceReply(conversation *telegram.Conversation, request *telegram.SendMessageRequest) {
+	request.ForceReply = true
+
+	// after button press the message replies to the bot's message, so user is addressed by mention instead
+	if conversation.LastCallbackQueryId != "" && telegram.IsGroupChatId(conversation.ChatId) {
+		request.Text = helpers.ConcatStrings(conversation.User.GetMention(), "\n\n", request.Text)
+	}
+}
+
 // Wait for user to enter marketplace URL.
 func (app *TelegramBotApp) waitForMarketplaceUrl(conversation *telegram.Conversation) {
 	url := conversation.LastMessage.Text
@@ -399,12 +1011,12 @@ func (app *TelegramBotApp) waitForMarketplaceUrl(conversation *telegram.Conversa
 		return
 	}
 
-	trackedProduct := conversation.GetContext(telegram.ConversationCtxProduct).(TrackedProduct)
+	trackedProduct := TrackedProduct{*conversation.Data.Product}
 
-	trackedProduct.marketplace = marketplaceType
-	trackedProduct.url = marketplace.GetCleanUrl(url)
+	trackedProduct.Marketplace = int(marketplaceType)
+	trackedProduct.Url = marketplace.GetCleanUrl(url)
 
-	conversation.StoreContext(telegram.ConversationCtxProduct, trackedProduct)
+	conversation.Data.Product = &trackedProduct.ProductDraft
 
 	model, err := app.findUserProductByUrl(conversation.ChatId, conversation.User.Id, trackedProduct.GetUrl())
 	if err != nil {
@@ -418,13 +1030,25 @@ func (app *TelegramBotApp) waitForMarketplaceUrl(conversation *telegram.Conversa
 	}
 
 	if model.Exists() {
+		condition := helpers.ConcatStrings(" станет ниже <b>", helpers.CurrencyFormat(helpers.CurrencyToMajor(model.ThresholdPrice)), "</b>\n")
+		if model.TargetPrice > 0 {
+			condition = helpers.ConcatStrings(" станет не выше <b>", helpers.CurrencyFormat(helpers.CurrencyToMajor(model.TargetPrice)), "</b>\n")
+		}
+
 		request.Text = helpers.ConcatStrings(
 			"Уже слежу :)\n\n",
 			"Я сообщу, когда цена на товар <b>«<a href=\"", model.Url, "\">", model.Title, "</a>»</b>",
-			" станет ниже <b>", helpers.CurrencyFormat(helpers.CurrencyToMajor(model.ThresholdPrice)), "</b>\n",
-			"<i>Теущая цена: ", helpers.CurrencyFormat(helpers.CurrencyToMajor(model.CurrentPrice)), "</i>",
+			condition,
+			"<i>Текущая цена: ", helpers.CurrencyFormat(helpers.CurrencyToMajor(model.CurrentPrice)), "</i>",
 		)
 
+		if !model.IsActive() {
+			request.Text = helpers.ConcatStrings(
+				request.Text, "\n\n",
+				"Но сейчас отслеживание приостановлено, чтобы возобновить, нажми ", telegram.CommandPrefixResumeProduct, model.Slug,
+			)
+		}
+
 		app.bot.SendMessage(conversation.ChatId, request)
 		conversation.Reset()
 		return
@@ -445,7 +1069,7 @@ func (app *TelegramBotApp) waitForMarketplaceUrl(conversation *telegram.Conversa
 	app.scrapeMarketplaceUrl(conversation)
 }
 
-// Scrape marketplace URL.
+// Add marketplace URL to scrape queue, result is shown when the job is done.
 func (app *TelegramBotApp) scrapeMarketplaceUrl(conversation *telegram.Conversation) {
 	sentMessage, err := app.bot.SendMessage(conversation.ChatId, telegram.SendMessageRequest{
 		ReplyToMessageId: conversation.LastMessage.MessageId,
@@ -453,233 +1077,1204 @@ func (app *TelegramBotApp) scrapeMarketplaceUrl(conversation *telegram.Conversat
 	})
 
 	if err != nil {
-		app.logger.Println(err)
+		app.logger.Println(err)
+		return
+	}
+
+	app.startScrapeLoader(conversation.ChatId, sentMessage.MessageId)
+
+	_, err = app.scrapeJobQueue.Enqueue(marketplace.ScrapeJob{
+		Url:               conversation.Data.Product.Url,
+		TelegramChatId:    conversation.ChatId,
+		TelegramUserId:    conversation.User.Id,
+		TelegramMessageId: sentMessage.MessageId,
+	})
+
+	if err != nil {
+		app.logger.Println("ERROR! Unable to enqueue scrape job:", err)
+
+		app.stopScrapeLoader(conversation.ChatId, sentMessage.MessageId)
+		app.bot.EditMessage(conversation.ChatId, sentMessage.MessageId, telegram.EditMessageRequest{
+			Text: "Что-то пошло не так...",
+		})
+
+		conversation.Reset()
+	}
+}
+
+// Animate loader message until scraping is done.
+func (app *TelegramBotApp) startScrapeLoader(chatId int, messageId int) {
+	isLoaderDone := make(chan bool)
+
+	app.scrapeLoaders.Store(app.getScrapeLoaderKey(chatId, messageId), isLoaderDone)
+
+	go func() {
+		loaderDotsCount := 3
+		loaderTicker := time.NewTicker(time.Second)
+
+		defer loaderTicker.Stop()
+
+		for {
+			select {
+			case <-isLoaderDone:
+				return
+			case <-loaderTicker.C:
+				loaderDotsCount++
+
+				if loaderDotsCount > 9 {
+					loaderDotsCount = 3
+				}
+
+				app.bot.EditMessage(chatId, messageId, telegram.EditMessageRequest{
+					Text: helpers.ConcatStrings("Ищу", strings.Repeat(".", loaderDotsCount)),
+				})
+			}
+		}
+	}()
+}
+
+// Stop loader animation (if it's running, e.g. it's not running after restart).
+func (app *TelegramBotApp) stopScrapeLoader(chatId int, messageId int) {
+	isLoaderDone, exists := app.scrapeLoaders.LoadAndDelete(app.getScrapeLoaderKey(chatId, messageId))
+	if exists {
+		close(isLoaderDone.(chan bool))
+	}
+}
+
+// Get key of loader message.
+func (app *TelegramBotApp) getScrapeLoaderKey(chatId int, messageId int) string {
+	return helpers.ConcatStrings(strconv.Itoa(chatId), "_", strconv.Itoa(messageId))
+}
+
+// Deliver result of scrape job to user, it's processed along with other updates of the same conversation.
+func (app *TelegramBotApp) onScrapeJobDone(job marketplace.ScrapeJob, scrapedProduct marketplace.ProductDto, err error) {
+	app.stopScrapeLoader(job.TelegramChatId, job.TelegramMessageId)
+
+	hash := app.calculateConversationHash(telegram.Message{
+		Chat: telegram.Chat{Id: job.TelegramChatId},
+		From: telegram.User{Id: job.TelegramUserId},
+	})
+
+	app.conversationManager.Dispatch(hash, func() {
+		conversation, exists := app.conversationStore.Find(hash)
+
+		// user has cancelled the search or started another one
+		if !exists ||
+			conversation.StateMachine.GetCurrentState() != marketplace.StateScraping ||
+			conversation.Data.Product == nil ||
+			conversation.Data.Product.Url != job.Url {
+			app.bot.EditMessage(job.TelegramChatId, job.TelegramMessageId, telegram.EditMessageRequest{
+				Text: "Поиск отменён",
+			})
+			return
+		}
+
+		app.showScrapedProduct(conversation, job.TelegramMessageId, scrapedProduct, err)
+
+		if err := app.conversationStore.Save(hash, conversation); err != nil {
+			app.logger.Println("ERROR! Unable to save conversation:", err)
+		}
+	})
+}
+
+// Save scraped product and show it in loader message.
+func (app *TelegramBotApp) showScrapedProduct(conversation *telegram.Conversation, messageId int, scrapedProduct marketplace.ProductDto, err error) {
+	request := telegram.EditMessageRequest{
+		LinkPreviewOptions: telegram.LinkPreviewOptions{
+			IsDisabled: true,
+		},
+	}
+
+	trackedProduct := TrackedProduct{*conversation.Data.Product}
+
+	if err == marketplace.ErrOutOfStock {
+		trackedProduct.OutOfStock = scrapedProduct.IsOutOfStock()
+	} else if err == marketplace.ErrNotFound {
+		request.Text = "Не могу найти товар по такой ссылке :("
+
+		app.bot.EditMessage(conversation.ChatId, messageId, request)
+		conversation.Reset()
+		return
+	} else if err != nil {
+		request.Text = "Что-то пошло не так..."
+
+		app.bot.EditMessage(conversation.ChatId, messageId, request)
+		conversation.Reset()
+		return
+	}
+
+	trackedProduct.ScrapedAt = scrapedProduct.GetScrapedAt()
+	trackedProduct.Title = scrapedProduct.GetTitle()
+	trackedProduct.CurrentPrice = scrapedProduct.GetCurrentPrice()
+	trackedProduct.ThresholdPrice = trackedProduct.GetCurrentPrice()
+
+	conversation.Data.Product = &trackedProduct.ProductDraft
+
+	model, err := app.marketplaceService.CreateWithinTransaction(&trackedProduct, func(transaction pgx.Tx, model marketplace.Product) error {
+		_, err := app.marketplaceService.AddPriceObservation(transaction, model, marketplace.GetScrapeMethod(scrapedProduct))
+		return err
+	})
+
+	if err != nil {
+		app.logger.Println("ERROR! Unable to create product:", err)
+
+		request.Text = "Не могу сохранить найденный товар :("
+
+		app.bot.EditMessage(conversation.ChatId, messageId, request)
+		conversation.Reset()
+		return
+	}
+
+	if model.OutOfStock {
+		request.Text = helpers.ConcatStrings(
+			"Начал отслеживать товар, но его пока нет в наличии ", string(telegram.EmojiWhiteFrowningFace), "\n\n",
+			"<b><a href=\"", model.Url, "\">", model.Title, "</a></b> (", marketplace.GetMarketplaceName(&model), ")\n\n",
+			"Сообщу, когда поступит в продажу",
+		)
+	} else {
+		request.Text = helpers.ConcatStrings(
+			"Начал отслеживать товар ", string(telegram.EmojiOkHand), "\n\n",
+			"<b><a href=\"", model.Url, "\">", model.Title, "</a></b> (", marketplace.GetMarketplaceName(&model), ")\n\n",
+			"Сообщу, когда цена станет ниже <b>", helpers.CurrencyFormat(helpers.CurrencyToMajor(model.ThresholdPrice)), "</b>",
+		)
+	}
+
+	app.bot.EditMessage(conversation.ChatId, messageId, request)
+
+	conversation.Data.ProductSlug = model.Slug
+
+	_, err = conversation.StateMachine.TriggerEvent(marketplace.EventAskForTargetPrice)
+	if err != nil {
+		app.logger.Println(helpers.ConcatStrings("ERROR! Unable to trigger state machine \"", string(marketplace.EventAskForTargetPrice), "\" event:"), err)
+		conversation.Reset()
+		return
+	}
+
+	// don't wait for next user input, proceed to asking for target price
+	app.askForTargetPrice(conversation)
+}
+
+// Send message to ask for product target price.
+func (app *TelegramBotApp) askForTargetPrice(conversation *telegram.Conversation) {
+	request := telegram.SendMessageRequest{
+		ReplyToMessageId: conversation.LastMessage.MessageId,
+		Text: helpers.ConcatStrings(
+			"Могу сообщить только тогда, когда цена опустится до нужной тебе отметки\n\n",
+			"Отправь мне целевую цену (например, <code>1500</code>)",
+			" или процент снижения от текущей цены (например, <code>10%</code>)",
+		),
+	}
+
+	// inline keyboard can't be sent along with forced reply, which is needed in group
+	if telegram.IsGroupChatId(conversation.ChatId) {
+		request.Text = helpers.ConcatStrings(request.Text, "\n\n", "Чтобы пропустить, отправь ", telegram.CommandSkip)
+	} else {
+		request.ReplyMarkup = telegram.InlineKeyboardMarkup{
+			Keyboard: [][]telegram.InlineKeyboardButton{
+				{
+					{
+						Text:         "Пропустить",
+						CallbackData: telegram.CommandSkip,
+					},
+				},
+			},
+		}
+	}
+
+	app.forceReply(conversation, &request)
+	app.bot.SendMessage(conversation.ChatId, request)
+
+	conversation.StateMachine.TriggerEvent(marketplace.EventWaitForTargetPrice)
+}
+
+// Send message to ask for a new target price of existing product.
+func (app *TelegramBotApp) editTargetPrice(conversation *telegram.Conversation) {
+	slug := strings.Replace(conversation.LastMessage.Text, telegram.CommandPrefixSetPrice, "", 1)
+	conversation.Data.ProductSlug = slug
+
+	model, err := app.findUserProductBySlug(conversation.ChatId, conversation.User.Id, slug)
+	if err != nil {
+		app.logErrorAndSendMessage(
+			conversation,
+			err,
+			"Unable to find user product by slug to edit target price",
+			"Не удалось найти товар",
+		)
+		return
+	}
+
+	request := telegram.SendMessageRequest{
+		ReplyToMessageId: conversation.LastMessage.MessageId,
+		LinkPreviewOptions: telegram.LinkPreviewOptions{
+			IsDisabled: true,
+		},
+	}
+
+	if !model.Exists() {
+		request.Text = "Нет такого товара"
+
+		app.bot.SendMessage(conversation.ChatId, request)
+		conversation.Reset()
+		return
+	}
+
+	if !app.checkChangeAllowed(conversation, model) {
+		conversation.Reset()
+		return
+	}
+
+	targetPrice := "не задана"
+	if model.TargetPrice > 0 {
+		targetPrice = helpers.CurrencyFormat(helpers.CurrencyToMajor(model.TargetPrice))
+	}
+
+	currentPrice := "нет в наличии"
+	if !model.OutOfStock {
+		currentPrice = helpers.CurrencyFormat(helpers.CurrencyToMajor(model.CurrentPrice))
+	}
+
+	request.Text = helpers.ConcatStrings(
+		"<b><a href=\"", model.Url, "\">", model.Title, "</a></b> (", marketplace.GetMarketplaceName(&model), ")\n\n",
+		"Текущая цена: <b>", currentPrice, "</b>\n",
+		"Целевая цена: <b>", targetPrice, "</b>\n\n",
+		"Отправь мне новую целевую цену (например, <code>1500</code>)",
+		" или процент снижения от текущей цены (например, <code>10%</code>)\n\n",
+		"Чтобы убрать целевую цену, отправь <code>0</code> или ", telegram.CommandSkip,
+	)
+
+	app.forceReply(conversation, &request)
+	app.bot.SendMessage(conversation.ChatId, request)
+
+	conversation.StateMachine.TriggerEvent(marketplace.EventWaitForTargetPrice)
+}
+
+// Wait for user to enter product target price.
+func (app *TelegramBotApp) waitForTargetPrice(conversation *telegram.Conversation) {
+	productSlug := conversation.Data.ProductSlug
+
+	product, err := app.findUserProductBySlug(conversation.ChatId, conversation.User.Id, productSlug)
+	if err != nil {
+		app.logErrorAndSendMessage(
+			conversation,
+			err,
+			"Unable to find user product by slug to set target price",
+			"Не удалось найти товар",
+		)
+		return
+	}
+
+	request := telegram.SendMessageRequest{
+		ReplyToMessageId: conversation.LastMessage.MessageId,
+		LinkPreviewOptions: telegram.LinkPreviewOptions{
+			IsDisabled: true,
+		},
+	}
+
+	if !product.Exists() {
+		request.Text = "Нет такого товара"
+
+		app.bot.SendMessage(conversation.ChatId, request)
+		conversation.Reset()
+		return
+	}
+
+	// target price is skipped for a new product or removed from existing one
+	if conversation.LastMessage.Text == telegram.CommandSkip || strings.TrimSpace(conversation.LastMessage.Text) == "0" {
+		app.bot.AnswerCallbackQuery(conversation.LastCallbackQueryId)
+
+		if product.TargetPrice > 0 {
+			product.TargetPrice = 0
+
+			if _, err := app.marketplaceService.Update(product.Id, &product); err != nil {
+				app.logErrorAndSendMessage(
+					conversation,
+					err,
+					"Unable to remove product target price",
+					"Не могу убрать целевую цену",
+				)
+				return
+			}
+		}
+
+		app.bot.SendMessage(conversation.ChatId, telegram.SendMessageRequest{
+			Text: helpers.ConcatStrings("Окей ", string(telegram.EmojiOkHand), "\n\nЦелевая цена не задана, поэтому по умолчанию сообщу о любом снижении цены"),
+		})
+
+		conversation.Reset()
+		return
+	}
+
+	targetPrice, err := marketplace.ParseTargetPrice(conversation.LastMessage.Text, product.CurrentPrice)
+
+	if err != nil {
+		switch err {
+		case marketplace.ErrTargetPriceTooHigh:
+			request.Text = helpers.ConcatStrings(
+				"Целевая цена должна быть ниже текущей (<b>", helpers.CurrencyFormat(helpers.CurrencyToMajor(product.CurrentPrice)), "</b>)",
+			)
+		case marketplace.ErrTargetPriceUnknownBase:
+			request.Text = "Товара нет в наличии, поэтому процент считать не от чего :(\n\nОтправь сумму, например <code>1500</code>"
+		default:
+			request.Text = "Не понимаю :(\n\nОтправь сумму (например, <code>1500</code>) или процент (например, <code>10%</code>)"
+		}
+
+		app.bot.SendMessage(conversation.ChatId, request)
+		return
+	}
+
+	product.TargetPrice = targetPrice
+
+	model, err := app.marketplaceService.Update(product.Id, &product)
+	if err != nil {
+		app.logErrorAndSendMessage(
+			conversation,
+			err,
+			"Unable to update product target price",
+			"Не могу сохранить целевую цену",
+		)
+		return
+	}
+
+	request.Text = helpers.ConcatStrings(
+		"Окей ", string(telegram.EmojiOkHand), "\n\n",
+		"Сообщу, когда цена на товар <b>«<a href=\"", model.Url, "\">", model.Title, "</a>»</b>",
+		" станет не выше <b>", helpers.CurrencyFormat(helpers.CurrencyToMajor(model.TargetPrice)), "</b>",
+	)
+
+	app.bot.SendMessage(conversation.ChatId, request)
+	conversation.Reset()
+}
+
+// Show notification rules of product and ask for the new ones.
+func (app *TelegramBotApp) editAlertRules(conversation *telegram.Conversation) {
+	slug := strings.Replace(conversation.LastMessage.Text, telegram.CommandPrefixAlertRules, "", 1)
+	conversation.Data.ProductSlug = slug
+
+	model, err := app.findUserProductBySlug(conversation.ChatId, conversation.User.Id, slug)
+	if err != nil {
+		app.logErrorAndSendMessage(
+			conversation,
+			err,
+			"Unable to find user product by slug to edit alert rules",
+			"Не удалось найти товар",
+		)
+		return
+	}
+
+	request := telegram.SendMessageRequest{
+		ReplyToMessageId: conversation.LastMessage.MessageId,
+		LinkPreviewOptions: telegram.LinkPreviewOptions{
+			IsDisabled: true,
+		},
+	}
+
+	if !model.Exists() {
+		request.Text = "Нет такого товара"
+
+		app.bot.SendMessage(conversation.ChatId, request)
+		conversation.Reset()
+		return
+	}
+
+	if !app.checkChangeAllowed(conversation, model) {
+		conversation.Reset()
+		return
+	}
+
+	rulesTitle := "Правила уведомлений"
+	if !app.marketplaceService.HasOwnAlertRules(model) {
+		rulesTitle = "Правила уведомлений (по умолчанию)"
+	}
+
+	request.Text = helpers.ConcatStrings(
+		"<b><a href=\"", model.Url, "\">", model.Title, "</a></b> (", marketplace.GetMarketplaceName(&model), ")\n\n",
+		rulesTitle, ":",
+	)
+
+	for _, rule := range app.marketplaceService.GetAlertRules(model) {
+		request.Text = helpers.ConcatStrings(request.Text, "\n", "• ", app.describeAlertRule(rule, &model))
+	}
+
+	request.Text = helpers.ConcatStrings(
+		request.Text, "\n\n",
+		"Отправь мне новые правила через запятую:\n",
+		"<code>-10%</code> — снижение цены на 10% и больше\n",
+		"<code>-500</code> — снижение цены на 500 ₽ и больше\n",
+		"<code>цель</code> — цена не выше целевой\n",
+		"<code>мин 30</code> — самая низкая цена за 30 дней\n",
+		"<code>наличие</code> — товар снова в продаже\n\n",
+		"Или <code>сброс</code>, чтобы вернуть правила по умолчанию",
+	)
+
+	app.forceReply(conversation, &request)
+	app.bot.SendMessage(conversation.ChatId, request)
+
+	conversation.StateMachine.TriggerEvent(marketplace.EventWaitForAlertRules)
+}
+
+// Wait for user to enter notification rules of product.
+func (app *TelegramBotApp) waitForAlertRules(conversation *telegram.Conversation) {
+	product, err := app.findUserProductBySlug(conversation.ChatId, conversation.User.Id, conversation.Data.ProductSlug)
+	if err != nil {
+		app.logErrorAndSendMessage(
+			conversation,
+			err,
+			"Unable to find user product by slug to set alert rules",
+			"Не удалось найти товар",
+		)
+		return
+	}
+
+	request := telegram.SendMessageRequest{
+		ReplyToMessageId: conversation.LastMessage.MessageId,
+		LinkPreviewOptions: telegram.LinkPreviewOptions{
+			IsDisabled: true,
+		},
+	}
+
+	if !product.Exists() {
+		request.Text = "Нет такого товара"
+
+		app.bot.SendMessage(conversation.ChatId, request)
+		conversation.Reset()
+		return
+	}
+
+	var rules []marketplace.AlertRule
+
+	input := strings.ToLower(strings.TrimSpace(conversation.LastMessage.Text))
+
+	if input != "сброс" && input != "reset" {
+		rules, err = marketplace.ParseAlertRules(input)
+		if err != nil {
+			request.Text = "Не понимаю :(\n\nОтправь правила через запятую, например <code>-10%, мин 30, наличие</code>"
+
+			app.bot.SendMessage(conversation.ChatId, request)
+			return
+		}
+	}
+
+	if err := app.marketplaceService.SetAlertRules(product, rules); err != nil {
+		app.logErrorAndSendMessage(
+			conversation,
+			err,
+			"Unable to save alert rules",
+			"Не могу сохранить правила",
+		)
+		return
+	}
+
+	request.Text = helpers.ConcatStrings(
+		"Окей ", string(telegram.EmojiOkHand), "\n\n",
+		"Сообщу о товаре <b>«<a href=\"", product.Url, "\">", product.Title, "</a>»</b>, когда:",
+	)
+
+	for _, rule := range app.marketplaceService.GetAlertRules(product) {
+		request.Text = helpers.ConcatStrings(request.Text, "\n", "• ", app.describeAlertRule(rule, &product))
+
+		if rule.Type == marketplace.AlertRuleTargetPrice && product.TargetPrice == 0 {
+			request.Text = helpers.ConcatStrings(request.Text, ", задать: ", telegram.CommandPrefixSetPrice, product.Slug)
+		}
+	}
+
+	app.bot.SendMessage(conversation.ChatId, request)
+	conversation.Reset()
+}
+
+// Show marketplace products listing.
+func (app *TelegramBotApp) showMarketplaceListing(conversation *telegram.Conversation) {
+	page := 1
+
+	command := conversation.LastMessage.Text
+
+	if strings.HasPrefix(command, telegram.CommandPrefixPage) {
+		parts := strings.Split(command, "_")
+
+		requestedPage, err := strconv.Atoi(parts[1])
+		if err != nil {
+			app.logErrorAndSendMessage(
+				conversation,
+				err,
+				"Unable to parse page number",
+				"Не могу определить номер страницы",
+			)
+			return
+		}
+
+		page = requestedPage
+	}
+
+	app.showMarketplaceListingPage(conversation, page)
+}
+
+// Show page of user's products list, list is edited in place if it's requested by inline button.
+func (app *TelegramBotApp) showMarketplaceListingPage(conversation *telegram.Conversation, page int) {
+	perPage := 5
+	ownerId := app.marketplaceService.GetWatchlistOwnerId(conversation.ChatId, conversation.User.Id)
+	result := app.marketplaceService.FindAllForUserPaginated(conversation.ChatId, ownerId, page, perPage)
+
+	// e.g. the last item of the last page is deleted
+	if len(result.Items) == 0 && result.Total > 0 {
+		result = app.marketplaceService.FindAllForUserPaginated(conversation.ChatId, ownerId, result.LastPage, perPage)
+	}
+
+	// callback query comes with the message of pressed button
+	messageId := conversation.LastMessage.MessageId
+	if conversation.Data.Message != nil {
+		messageId = conversation.Data.Message.MessageId
+	}
+
+	if result.Total == 0 {
+		text := helpers.ConcatStrings(
+			"У тебя пока нет отслеживаемых товаров\n\n",
+			"Воспользуйся командой <code>", telegram.CommandTrackProduct, "</code> чтобы начать",
+		)
+
+		if conversation.LastCallbackQueryId != "" {
+			app.bot.EditMessage(conversation.ChatId, messageId, telegram.EditMessageRequest{
+				Text: text,
+			})
+			app.bot.AnswerCallbackQuery(conversation.LastCallbackQueryId)
+		} else {
+			app.bot.SendMessage(conversation.ChatId, telegram.SendMessageRequest{
+				Text:             text,
+				ReplyToMessageId: conversation.LastMessage.MessageId,
+			})
+		}
+
+		conversation.Reset()
+		return
+	}
+
+	listMessage := "<b>Отслеживаемые товары</b>:\n\n"
+	keyboard := [][]telegram.InlineKeyboardButton{}
+
+	for key, item := range result.Items {
+		model := item.(marketplace.Product)
+
+		position := key + 1
+		if result.CurrentPage > 1 {
+			position += int(result.CurrentPage-1) * int(result.PerPage)
+		}
+
+		itemMessage := helpers.ConcatStrings(
+			"<b>", strconv.Itoa(position), ". <a href=\"", model.Url, "\">", model.Title, "</a></b>",
+			" (", marketplace.GetMarketplaceName(&model), ")",
+		)
+
+		if !model.IsActive() {
+			itemMessage = helpers.ConcatStrings(
+				itemMessage,
+				"\n",
+				"• ", string(telegram.EmojiPauseButton), " <b>Отслеживание приостановлено</b>",
+			)
+		}
+
+		if model.OutOfStock {
+			itemMessage = helpers.ConcatStrings(
+				itemMessage,
+				"\n",
+				"• <b>Нет в наличии</b>",
+				" (<i>", helpers.TimeToHuman(model.ScrapedAt.In(app.timeLocation)), "</i>)",
+			)
+		} else {
+			itemMessage = helpers.ConcatStrings(
+				itemMessage,
+				"\n",
+				"• <b>", helpers.CurrencyFormat(helpers.CurrencyToMajor(model.CurrentPrice)), "</b>",
+				" (<i>", helpers.TimeToHuman(model.ScrapedAt.In(app.timeLocation)), "</i>)",
+			)
+		}
+
+		if model.TargetPrice > 0 {
+			itemMessage = helpers.ConcatStrings(
+				itemMessage,
+				"\n",
+				"• Целевая цена: ", helpers.CurrencyFormat(helpers.CurrencyToMajor(model.TargetPrice)),
+			)
+		}
+
+		listMessage = helpers.ConcatStrings(listMessage, itemMessage, "\n\n")
+		keyboard = append(keyboard, app.buildProductActionsKeyboard(model, position, result.CurrentPage))
+	}
+
+	if pageNav := app.buildPageNavigationKeyboard(result); len(pageNav) > 0 {
+		keyboard = append(keyboard, pageNav)
+	}
+
+	// edit existing message with products list
+	if conversation.LastCallbackQueryId != "" {
+		request := telegram.EditMessageRequest{
+			Text: listMessage,
+			LinkPreviewOptions: telegram.LinkPreviewOptions{
+				IsDisabled: true,
+			},
+			ReplyMarkup: telegram.InlineKeyboardMarkup{
+				Keyboard: keyboard,
+			},
+		}
+
+		app.bot.EditMessage(conversation.ChatId, messageId, request)
+		app.bot.AnswerCallbackQuery(conversation.LastCallbackQueryId)
+
+		return
+	}
+
+	request := telegram.SendMessageRequest{
+		Text:             listMessage,
+		ReplyToMessageId: conversation.LastMessage.MessageId,
+		LinkPreviewOptions: telegram.LinkPreviewOptions{
+			IsDisabled: true,
+		},
+		ReplyMarkup: telegram.InlineKeyboardMarkup{
+			Keyboard: keyboard,
+		},
+	}
+
+	sentMessage, err := app.bot.SendMessage(conversation.ChatId, request)
+	if err != nil {
+		app.logger.Println("ERROR! Unable to send listing message:", err)
+		return
+	}
+
+	conversation.Data.Message = &sentMessage
+}
+
+// Create inline keyboard with actions of listed product.
+func (app *TelegramBotApp) buildProductActionsKeyboard(model marketplace.Product, position int, page int) []telegram.InlineKeyboardButton {
+	pauseEmoji, pauseAction := telegram.EmojiPauseButton, telegram.CallbackActionPause
+
+	if !model.IsActive() {
+		pauseEmoji, pauseAction = telegram.EmojiPlayButton, telegram.CallbackActionResume
+	}
+
+	buttons := []telegram.InlineKeyboardButton{
+		{
+			Text: helpers.ConcatStrings(strconv.Itoa(position), " ", string(telegram.EmojiLink)),
+			Url:  model.Url,
+		},
+	}
+
+	buttons = app.appendCallbackButton(buttons, string(telegram.EmojiChartIncreasing), telegram.CallbackActionHistory, model.Slug, page)
+	buttons = app.appendCallbackButton(buttons, string(telegram.EmojiPencil), telegram.CallbackActionSetPrice, model.Slug, page)
+	buttons = app.appendCallbackButton(buttons, string(telegram.EmojiBell), telegram.CallbackActionAlertRules, model.Slug, page)
+	buttons = app.appendCallbackButton(buttons, string(pauseEmoji), pauseAction, model.Slug, page)
+	buttons = app.appendCallbackButton(buttons, string(telegram.EmojiWastebasket), telegram.CallbackActionDelete, model.Slug, page)
+
+	return buttons
+}
+
+// Add inline button with callback data, button is skipped if its data doesn't fit the limit,
+// since Telegram rejects the whole message with invalid button.
+func (app *TelegramBotApp) appendCallbackButton(buttons []telegram.InlineKeyboardButton, text string, action telegram.CallbackAction, slug string, page int) []telegram.InlineKeyboardButton {
+	data, err := telegram.CallbackData{Action: action, Slug: slug, Page: page}.ToString()
+	if err != nil {
+		app.logger.Println("ERROR! Unable to build callback data of", slug, ":", err)
+		return buttons
+	}
+
+	return append(buttons, telegram.InlineKeyboardButton{
+		Text:         text,
+		CallbackData: data,
+	})
+}
+
+// Process action of inline button from products list.
+func (app *TelegramBotApp) processCallbackAction(conversation *telegram.Conversation, data telegram.CallbackData) {
+	// list is edited in place, so keep the message which the button belongs to
+	listMessage := conversation.LastMessage
+	conversation.Data.Message = &listMessage
+
+	switch data.Action {
+	case telegram.CallbackActionHistory:
+		app.bot.AnswerCallbackQuery(conversation.LastCallbackQueryId)
+
+		conversation.LastMessage.Text = helpers.ConcatStrings(telegram.CommandPrefixPriceHistory, data.Slug)
+		app.showPriceHistory(conversation)
+	case telegram.CallbackActionSetPrice:
+		app.bot.AnswerCallbackQuery(conversation.LastCallbackQueryId)
+
+		conversation.LastMessage.Text = helpers.ConcatStrings(telegram.CommandPrefixSetPrice, data.Slug)
+		conversation.StateMachine = marketplace.NewFsm()
+		conversation.StateMachine.TriggerEvent(marketplace.EventEditTargetPrice)
+
+		app.processStateMachine(conversation)
+	case telegram.CallbackActionAlertRules:
+		app.bot.AnswerCallbackQuery(conversation.LastCallbackQueryId)
+
+		conversation.LastMessage.Text = helpers.ConcatStrings(telegram.CommandPrefixAlertRules, data.Slug)
+		conversation.StateMachine = marketplace.NewFsm()
+		conversation.StateMachine.TriggerEvent(marketplace.EventEditAlertRules)
+
+		app.processStateMachine(conversation)
+	case telegram.CallbackActionPause, telegram.CallbackActionResume:
+		model, err := app.findUserProductBySlug(conversation.ChatId, conversation.User.Id, data.Slug)
+		if err == nil && model.Exists() && !app.checkChangeAllowed(conversation, model) {
+			app.bot.AnswerCallbackQuery(conversation.LastCallbackQueryId)
+			return
+		}
+
+		if err == nil && model.Exists() {
+			if data.Action == telegram.CallbackActionResume {
+				err = app.marketplaceService.Resume(model.Id)
+			} else {
+				err = app.marketplaceService.Pause(model.Id)
+			}
+		}
+
+		if err != nil {
+			app.logger.Println("ERROR! Unable to pause/resume product:", err)
+		}
+
+		app.showMarketplaceListingPage(conversation, data.Page)
+	case telegram.CallbackActionDelete:
+		if !app.checkDeleteAllowed(conversation) {
+			return
+		}
+
+		app.confirmListedProductDelete(conversation, data)
+	case telegram.CallbackActionConfirmDelete:
+		if !app.checkDeleteAllowed(conversation) {
+			return
+		}
+
+		model, err := app.findUserProductBySlug(conversation.ChatId, conversation.User.Id, data.Slug)
+		if err == nil && model.Exists() {
+			app.marketplaceService.Delete(model.Id)
+		}
+
+		if err != nil {
+			app.logger.Println("ERROR! Unable to find user product by slug to delete:", err)
+		}
+
+		app.showMarketplaceListingPage(conversation, data.Page)
+	case telegram.CallbackActionCancelDelete:
+		app.showMarketplaceListingPage(conversation, data.Page)
+	default:
+		app.bot.AnswerCallbackQuery(conversation.LastCallbackQueryId)
+	}
+}
+
+// Ask to confirm delete of listed product by editing the list.
+func (app *TelegramBotApp) confirmListedProductDelete(conversation *telegram.Conversation, data telegram.CallbackData) {
+	model, err := app.findUserProductBySlug(conversation.ChatId, conversation.User.Id, data.Slug)
+	if err != nil || !model.Exists() {
+		app.showMarketplaceListingPage(conversation, data.Page)
+		return
+	}
+
+	var buttons []telegram.InlineKeyboardButton
+
+	buttons = app.appendCallbackButton(buttons, helpers.ConcatStrings(string(telegram.EmojiWhiteCheckMark), " Да"), telegram.CallbackActionConfirmDelete, model.Slug, data.Page)
+	buttons = app.appendCallbackButton(buttons, helpers.ConcatStrings(string(telegram.EmojiX), " Нет"), telegram.CallbackActionCancelDelete, model.Slug, data.Page)
+
+	// nothing to confirm without buttons
+	if len(buttons) < 2 {
+		app.showMarketplaceListingPage(conversation, data.Page)
+		return
+	}
+
+	request := telegram.EditMessageRequest{
+		Text: helpers.ConcatStrings(
+			"Точно хочешь удалить товар?\n\n",
+			"<b><a href=\"", model.Url, "\">", model.Title, "</a></b>",
+			" (", marketplace.GetMarketplaceName(&model), ")",
+		),
+		LinkPreviewOptions: telegram.LinkPreviewOptions{
+			IsDisabled: true,
+		},
+		ReplyMarkup: telegram.InlineKeyboardMarkup{
+			Keyboard: [][]telegram.InlineKeyboardButton{buttons},
+		},
+	}
+
+	app.bot.EditMessage(conversation.ChatId, conversation.Data.Message.MessageId, request)
+	app.bot.AnswerCallbackQuery(conversation.LastCallbackQueryId)
+}
+
+// Send product price history chart.
+func (app *TelegramBotApp) showPriceHistory(conversation *telegram.Conversation) {
+	const periodInDays = 30
+
+	slug := strings.Replace(conversation.LastMessage.Text, telegram.CommandPrefixPriceHistory, "", 1)
+
+	model, err := app.findUserProductBySlug(conversation.ChatId, conversation.User.Id, slug)
+	if err != nil {
+		app.logErrorAndSendMessage(
+			conversation,
+			err,
+			"Unable to find user product by slug to show price history",
+			"Не удалось найти товар",
+		)
+		return
+	}
+
+	request := telegram.SendMessageRequest{
+		ReplyToMessageId: conversation.LastMessage.MessageId,
+	}
+
+	if !model.Exists() {
+		request.Text = "Нет такого товара"
+
+		app.bot.SendMessage(conversation.ChatId, request)
+		return
+	}
+
+	currentTime := time.Now()
+	history := app.marketplaceService.GetPriceHistory(model.Id, currentTime.AddDate(0, 0, -periodInDays), currentTime)
+
+	points := make([]chart.Point, len(history))
+	minPrice := 0
+	maxPrice := 0
+
+	for i, observation := range history {
+		points[i] = chart.Point{
+			Time:       observation.ScrapedAt.In(app.timeLocation),
+			Price:      observation.Price,
+			OutOfStock: observation.OutOfStock,
+		}
+
+		if observation.OutOfStock {
+			continue
+		}
+
+		if minPrice == 0 || observation.Price < minPrice {
+			minPrice = observation.Price
+		}
+
+		if observation.Price > maxPrice {
+			maxPrice = observation.Price
+		}
+	}
+
+	priceChart := chart.NewPriceChart(800, 400)
+
+	image, err := priceChart.Render(points)
+	if err == chart.ErrNoData {
+		request.Text = helpers.ConcatStrings("Пока нет истории цен на этот товар за последние ", strconv.Itoa(periodInDays), " дней")
+
+		app.bot.SendMessage(conversation.ChatId, request)
+		return
+	}
+
+	if err != nil {
+		app.logErrorAndSendMessage(
+			conversation,
+			err,
+			"Unable to render price history chart",
+			"Не могу нарисовать график",
+		)
+		return
+	}
+
+	currentPrice := "нет в наличии"
+	if !model.OutOfStock {
+		currentPrice = helpers.CurrencyFormat(helpers.CurrencyToMajor(model.CurrentPrice))
+	}
+
+	photoRequest := telegram.SendPhotoRequest{
+		ReplyToMessageId: conversation.LastMessage.MessageId,
+		Photo:            image,
+		FileName:         helpers.ConcatStrings(model.Slug, ".png"),
+		Caption: helpers.ConcatStrings(
+			"<b><a href=\"", model.Url, "\">", model.Title, "</a></b> (", marketplace.GetMarketplaceName(&model), ")\n\n",
+			"Цены за последние ", strconv.Itoa(periodInDays), " дней:\n",
+			"• Минимальная: <b>", helpers.CurrencyFormat(helpers.CurrencyToMajor(minPrice)), "</b>\n",
+			"• Максимальная: <b>", helpers.CurrencyFormat(helpers.CurrencyToMajor(maxPrice)), "</b>\n",
+			"• Текущая: <b>", currentPrice, "</b>",
+		),
+	}
+
+	_, err = app.bot.SendPhoto(conversation.ChatId, photoRequest)
+	if err != nil {
+		app.logger.Println("ERROR! Unable to send price history chart:", err)
+	}
+}
+
+// Pause (or resume) tracking of product.
+func (app *TelegramBotApp) setMarketplaceProductActive(conversation *telegram.Conversation, isActive bool) {
+	commandPrefix := telegram.CommandPrefixPauseProduct
+	if isActive {
+		commandPrefix = telegram.CommandPrefixResumeProduct
+	}
+
+	slug := strings.Replace(conversation.LastMessage.Text, commandPrefix, "", 1)
+
+	model, err := app.findUserProductBySlug(conversation.ChatId, conversation.User.Id, slug)
+	if err != nil {
+		app.logErrorAndSendMessage(
+			conversation,
+			err,
+			"Unable to find user product by slug to pause/resume",
+			"Не удалось найти товар",
+		)
 		return
 	}
 
-	request := telegram.EditMessageRequest{
+	request := telegram.SendMessageRequest{
+		ReplyToMessageId: conversation.LastMessage.MessageId,
 		LinkPreviewOptions: telegram.LinkPreviewOptions{
 			IsDisabled: true,
 		},
 	}
 
-	loaderDotsCount := 3
-	loaderTicker := time.NewTicker(time.Second)
-	isLoaderDone := make(chan bool)
-
-	go func() {
-		for {
-			select {
-			case <-isLoaderDone:
-				loaderTicker.Stop()
-
-				return
-			case <-loaderTicker.C:
-				loaderDotsCount++
-
-				if loaderDotsCount > 9 {
-					loaderDotsCount = 3
-				}
+	if !model.Exists() {
+		request.Text = "Нет такого товара"
 
-				request.Text = helpers.ConcatStrings("Ищу", strings.Repeat(".", loaderDotsCount))
+		app.bot.SendMessage(conversation.ChatId, request)
+		return
+	}
 
-				app.bot.EditMessage(conversation.ChatId, sentMessage.MessageId, request)
-			}
-		}
-	}()
+	if !app.checkChangeAllowed(conversation, model) {
+		return
+	}
 
-	trackedProduct := conversation.GetContext(telegram.ConversationCtxProduct).(TrackedProduct)
+	if isActive {
+		err = app.marketplaceService.Resume(model.Id)
+	} else {
+		err = app.marketplaceService.Pause(model.Id)
+	}
 
-	scraper := marketplace.NewScraper(app.logger, app.scraperTimeoutInSeconds)
-	scrapedProduct, err := scraper.Scrape(trackedProduct.GetUrl())
+	if err != nil {
+		app.logErrorAndSendMessage(
+			conversation,
+			err,
+			"Unable to pause/resume product",
+			"Не могу изменить отслеживание товара",
+		)
+		return
+	}
 
-	isLoaderDone <- true
+	if isActive {
+		request.Text = helpers.ConcatStrings(
+			"Возобновил отслеживание товара ", string(telegram.EmojiOkHand), "\n\n",
+			"<b><a href=\"", model.Url, "\">", model.Title, "</a></b> (", marketplace.GetMarketplaceName(&model), ")",
+		)
+	} else {
+		request.Text = helpers.ConcatStrings(
+			"Приостановил отслеживание товара ", string(telegram.EmojiPauseButton), "\n\n",
+			"<b><a href=\"", model.Url, "\">", model.Title, "</a></b> (", marketplace.GetMarketplaceName(&model), ")\n\n",
+			"Чтобы возобновить, нажми ", telegram.CommandPrefixResumeProduct, model.Slug,
+		)
+	}
 
-	if err == marketplace.ErrOutOfStock {
-		trackedProduct.outOfStock = scrapedProduct.IsOutOfStock()
-	} else if err == marketplace.ErrNotFound {
-		request.Text = "Не могу найти товар по такой ссылке :("
+	app.bot.SendMessage(conversation.ChatId, request)
+}
 
-		app.bot.EditMessage(conversation.ChatId, sentMessage.MessageId, request)
-		conversation.Reset()
-		return
-	} else if err != nil {
-		request.Text = "Что-то пошло не так..."
+// Pause (or resume) tracking of all user's products.
+func (app *TelegramBotApp) setAllMarketplaceProductsActive(conversation *telegram.Conversation, isActive bool) {
+	var count int
+	var err error
 
-		app.bot.EditMessage(conversation.ChatId, sentMessage.MessageId, request)
-		conversation.Reset()
+	// products of shared watchlist belong to all members of chat
+	if app.marketplaceService.IsSharedWatchlist(conversation.ChatId) && !app.isChatAdmin(conversation) {
+		app.bot.SendMessage(conversation.ChatId, telegram.SendMessageRequest{
+			ReplyToMessageId: conversation.LastMessage.MessageId,
+			Text:             "Приостановить или возобновить весь общий список могут только администраторы чата",
+		})
 		return
 	}
 
-	trackedProduct.scrapedAt = scrapedProduct.GetScrapedAt()
-	trackedProduct.title = scrapedProduct.GetTitle()
-	trackedProduct.currentPrice = scrapedProduct.GetCurrentPrice()
-	trackedProduct.thresholdPrice = trackedProduct.GetCurrentPrice()
+	ownerId := app.marketplaceService.GetWatchlistOwnerId(conversation.ChatId, conversation.User.Id)
 
-	conversation.StoreContext(telegram.ConversationCtxProduct, trackedProduct)
+	if isActive {
+		count, err = app.marketplaceService.ResumeAllForUser(conversation.ChatId, ownerId)
+	} else {
+		count, err = app.marketplaceService.PauseAllForUser(conversation.ChatId, ownerId)
+	}
 
-	model, err := app.marketplaceService.Create(&trackedProduct)
 	if err != nil {
-		app.logger.Println("ERROR! Unable to create product:", err)
-
-		request.Text = "Не могу сохранить найденный товар :("
-
-		app.bot.EditMessage(conversation.ChatId, sentMessage.MessageId, request)
+		app.logErrorAndSendMessage(
+			conversation,
+			err,
+			"Unable to pause/resume all products",
+			"Не могу изменить отслеживание товаров",
+		)
 		return
 	}
 
-	if model.OutOfStock {
+	request := telegram.SendMessageRequest{
+		ReplyToMessageId: conversation.LastMessage.MessageId,
+	}
+
+	if isActive && count == 0 {
+		request.Text = "Нет приостановленных товаров"
+	} else if isActive {
 		request.Text = helpers.ConcatStrings(
-			"Начал отслеживать товар, но его пока нет в наличии ", string(telegram.EmojiWhiteFrowningFace), "\n\n",
-			"<b><a href=\"", model.Url, "\">", model.Title, "</a></b> (", marketplace.GetMarketplaceName(&model), ")\n\n",
-			"Сообщу, когда поступит в продажу",
+			"Возобновил отслеживание товаров: ", strconv.Itoa(count), " ", string(telegram.EmojiOkHand),
 		)
+	} else if count == 0 {
+		request.Text = "Нет товаров, которые можно приостановить"
 	} else {
 		request.Text = helpers.ConcatStrings(
-			"Начал отслеживать товар ", string(telegram.EmojiOkHand), "\n\n",
-			"<b><a href=\"", model.Url, "\">", model.Title, "</a></b> (", marketplace.GetMarketplaceName(&model), ")\n\n",
-			"Сообщу, когда цена станет ниже <b>", helpers.CurrencyFormat(helpers.CurrencyToMajor(model.ThresholdPrice)), "</b>",
+			"Приостановил отслеживание товаров: ", strconv.Itoa(count), " ", string(telegram.EmojiPauseButton), "\n\n",
+			"Чтобы возобновить, используй команду <code>", telegram.CommandResumeAll, "</code>",
 		)
 	}
 
-	app.bot.EditMessage(conversation.ChatId, sentMessage.MessageId, request)
-	conversation.Reset()
+	app.bot.SendMessage(conversation.ChatId, request)
 }
 
-// Show marketplace products listing.
-func (app *TelegramBotApp) showMarketplaceListing(conversation *telegram.Conversation) {
-	page := 1
-
-	command := conversation.LastMessage.Text
-
-	if strings.HasPrefix(command, telegram.CommandPrefixPage) {
-		parts := strings.Split(command, "_")
-
-		requestedPage, err := strconv.Atoi(parts[1])
-		if err != nil {
-			app.logErrorAndSendMessage(
-				conversation,
-				err,
-				"Unable to parse page number",
-				"Не могу определить номер страницы",
-			)
-			return
-		}
+// Set timezone of user (digest is sent according to it).
+func (app *TelegramBotApp) setUserTimezone(conversation *telegram.Conversation) {
+	timezone := strings.TrimSpace(strings.TrimPrefix(conversation.LastMessage.Text, telegram.CommandTimezone))
+	settings := app.notificationService.GetSettings(conversation.ChatId, conversation.User.Id)
 
-		page = requestedPage
+	request := telegram.SendMessageRequest{
+		ReplyToMessageId: conversation.LastMessage.MessageId,
 	}
 
-	perPage := 5
-	result := app.marketplaceService.FindAllForUserPaginated(conversation.ChatId, conversation.User.Id, page, perPage)
-
-	if result.Total == 0 {
-		request := telegram.SendMessageRequest{
-			Text: helpers.ConcatStrings(
-				"У тебя пока нет отслеживаемых товаров\n\n",
-				"Воспользуйся командой <code>", telegram.CommandTrackProduct, "</code> чтобы начать",
-			),
-			ReplyToMessageId: conversation.LastMessage.MessageId,
-		}
+	location, err := time.LoadLocation(timezone)
+	if timezone == "" || timezone == "Local" || err != nil {
+		request.Text = helpers.ConcatStrings(
+			"Не знаю такой часовой пояс ", string(telegram.EmojiNeutralFace), "\n\n",
+			"Текущий: <b>", settings.GetLocation(app.timeLocation).String(), "</b>\n",
+			"Отправь команду вида <code>", telegram.CommandTimezone, " Europe/Moscow</code>",
+		)
 
 		app.bot.SendMessage(conversation.ChatId, request)
-		conversation.Reset()
 		return
 	}
 
-	listMessage := "<b>Отслеживаемые товары</b>:\n\n"
+	settings.Timezone = location.String()
 
-	for key, item := range result.Items {
-		model := item.(marketplace.Product)
+	if _, err := app.notificationService.SaveSettings(settings); err != nil {
+		app.logErrorAndSendMessage(conversation, err, "Unable to save user timezone", "Не могу сохранить часовой пояс")
+		return
+	}
 
-		position := key + 1
-		if result.CurrentPage > 1 {
-			position += int(result.CurrentPage-1) * int(result.PerPage)
-		}
+	request.Text = helpers.ConcatStrings(
+		"Часовой пояс изменён на <b>", settings.Timezone, "</b> ", string(telegram.EmojiOkHand), "\n",
+		"Сейчас там ", time.Now().In(location).Format("15:04"),
+	)
 
-		itemMessage := helpers.ConcatStrings(
-			"<b>", strconv.Itoa(position), ". <a href=\"", model.Url, "\">", model.Title, "</a></b>",
-			" (", marketplace.GetMarketplaceName(&model), ")",
-		)
+	app.bot.SendMessage(conversation.ChatId, request)
+}
 
-		if model.OutOfStock {
-			itemMessage = helpers.ConcatStrings(
-				itemMessage,
-				"\n",
-				"• <b>Нет в наличии</b>",
-				" (<i>", helpers.TimeToHuman(model.ScrapedAt.In(app.timeLocation)), "</i>)",
-			)
-		} else {
-			itemMessage = helpers.ConcatStrings(
-				itemMessage,
-				"\n",
-				"• <b>", helpers.CurrencyFormat(helpers.CurrencyToMajor(model.CurrentPrice)), "</b>",
-				" (<i>", helpers.TimeToHuman(model.ScrapedAt.In(app.timeLocation)), "</i>)",
-			)
-		}
+// Show notification settings of user with inline buttons to change them.
+func (app *TelegramBotApp) showNotificationSettings(conversation *telegram.Conversation) {
+	settings := app.notificationService.GetSettings(conversation.ChatId, conversation.User.Id)
 
-		itemMessage = helpers.ConcatStrings(
-			itemMessage,
-			"\n",
-			"• Удалить: ",
-			telegram.CommandPrefixDeleteProduct,
-			model.Slug,
-		)
+	app.bot.SendMessage(conversation.ChatId, telegram.SendMessageRequest{
+		ReplyToMessageId: conversation.LastMessage.MessageId,
+		Text:             app.buildNotificationSettingsText(settings),
+		ReplyMarkup: telegram.InlineKeyboardMarkup{
+			Keyboard: app.buildNotificationSettingsKeyboard(settings),
+		},
+	})
+}
 
-		listMessage = helpers.ConcatStrings(listMessage, itemMessage, "\n\n")
+// Change notification setting by inline button and update the settings message in place.
+func (app *TelegramBotApp) toggleNotificationSetting(conversation *telegram.Conversation) {
+	settings := app.notificationService.GetSettings(conversation.ChatId, conversation.User.Id)
+
+	switch strings.TrimPrefix(conversation.LastMessage.Text, telegram.CommandPrefixToggleSetting) {
+	case settingPriceIncrease:
+		settings.NotifyPriceIncrease = !settings.NotifyPriceIncrease
+	case settingSoldOut:
+		settings.NotifySoldOut = !settings.NotifySoldOut
+	case settingUnchangedDigest:
+		settings.UnchangedDigestDays = notification.GetNextUnchangedDigestDays(settings.UnchangedDigestDays)
+	case settingDigestMode:
+		settings.DigestMode = notification.GetNextDigestMode(settings.DigestMode)
+	case settingDigestHour:
+		settings.DigestHour = notification.GetNextDigestHour(settings.DigestHour)
+	default:
+		app.bot.AnswerCallbackQuery(conversation.LastCallbackQueryId)
+		return
 	}
 
-	pageNavKeyboard := [][]telegram.InlineKeyboardButton{
-		{},
+	settings, err := app.notificationService.SaveSettings(settings)
+	if err != nil {
+		app.bot.AnswerCallbackQuery(conversation.LastCallbackQueryId)
+		app.logErrorAndSendMessage(conversation, err, "Unable to save notification settings", "Не могу сохранить настройки")
+		return
 	}
 
-	if pageNav := app.buildPageNavigationKeyboard(result); len(pageNav) > 0 {
-		pageNavKeyboard[0] = pageNav
+	if conversation.LastCallbackQueryId == "" {
+		app.showNotificationSettings(conversation)
+		return
 	}
 
-	// edit existing message with products list
-	if conversation.LastCallbackQueryId != "" {
-		request := telegram.EditMessageRequest{
-			Text: listMessage,
-			LinkPreviewOptions: telegram.LinkPreviewOptions{
-				IsDisabled: true,
-			},
-		}
+	app.bot.EditMessage(conversation.ChatId, conversation.LastMessage.MessageId, telegram.EditMessageRequest{
+		Text: app.buildNotificationSettingsText(settings),
+		ReplyMarkup: telegram.InlineKeyboardMarkup{
+			Keyboard: app.buildNotificationSettingsKeyboard(settings),
+		},
+	})
+	app.bot.AnswerCallbackQuery(conversation.LastCallbackQueryId)
+}
 
-		if len(pageNavKeyboard[0]) > 0 {
-			request.ReplyMarkup = telegram.InlineKeyboardMarkup{
-				Keyboard: pageNavKeyboard,
-			}
-		}
+func (app *TelegramBotApp) buildNotificationSettingsText(settings notification.UserSettings) string {
+	return helpers.ConcatStrings(
+		"Настройки уведомлений ", string(telegram.EmojiGear), "\n\n",
+		"Снижение цены и появление в продаже настраиваются правилами товара, ",
+		"а здесь можно включить дополнительные уведомления ",
+		"или получать все уведомления одной сводкой (еженедельная приходит по понедельникам)\n\n",
+		"Часовой пояс: <b>", settings.GetLocation(app.timeLocation).String(), "</b>\n",
+		"Чтобы изменить его, отправь команду вида <code>", telegram.CommandTimezone, " Europe/Moscow</code>",
+	)
+}
 
-		sentMessage := conversation.GetContext(telegram.ConversationCtxMessage).(telegram.Message)
+func (app *TelegramBotApp) buildNotificationSettingsKeyboard(settings notification.UserSettings) [][]telegram.InlineKeyboardButton {
+	unchangedDigest := "выкл."
+	if settings.UnchangedDigestDays > 0 {
+		unchangedDigest = helpers.ConcatStrings(strconv.Itoa(settings.UnchangedDigestDays), " дн.")
+	}
 
-		app.bot.EditMessage(conversation.ChatId, sentMessage.MessageId, request)
-		app.bot.AnswerCallbackQuery(conversation.LastCallbackQueryId)
+	keyboard := [][]telegram.InlineKeyboardButton{
+		{
+			{
+				Text:         helpers.ConcatStrings(app.getSettingEmoji(settings.NotifyPriceIncrease), " Рост цены"),
+				CallbackData: helpers.ConcatStrings(telegram.CommandPrefixToggleSetting, settingPriceIncrease),
+			},
+		},
+		{
+			{
+				Text:         helpers.ConcatStrings(app.getSettingEmoji(settings.NotifySoldOut), " Товар закончился"),
+				CallbackData: helpers.ConcatStrings(telegram.CommandPrefixToggleSetting, settingSoldOut),
+			},
+		},
+		{
+			{
+				Text:         helpers.ConcatStrings(string(telegram.EmojiHourglass), " Цена не менялась: ", unchangedDigest),
+				CallbackData: helpers.ConcatStrings(telegram.CommandPrefixToggleSetting, settingUnchangedDigest),
+			},
+		},
+	}
 
-		return
+	digestMode := "выкл."
+
+	switch settings.DigestMode {
+	case notification.DigestModeDaily:
+		digestMode = "ежедневно"
+	case notification.DigestModeWeekly:
+		digestMode = "еженедельно"
 	}
 
-	request := telegram.SendMessageRequest{
-		Text:             listMessage,
-		ReplyToMessageId: conversation.LastMessage.MessageId,
-		LinkPreviewOptions: telegram.LinkPreviewOptions{
-			IsDisabled: true,
+	row := []telegram.InlineKeyboardButton{
+		{
+			Text:         helpers.ConcatStrings(string(telegram.EmojiBell), " Сводка: ", digestMode),
+			CallbackData: helpers.ConcatStrings(telegram.CommandPrefixToggleSetting, settingDigestMode),
 		},
 	}
 
-	if len(pageNavKeyboard[0]) > 0 {
-		request.ReplyMarkup = telegram.InlineKeyboardMarkup{
-			Keyboard: pageNavKeyboard,
-		}
+	if settings.IsDigestEnabled() {
+		row = append(row, telegram.InlineKeyboardButton{
+			Text:         helpers.ConcatStrings("в ", strconv.Itoa(settings.DigestHour), ":00"),
+			CallbackData: helpers.ConcatStrings(telegram.CommandPrefixToggleSetting, settingDigestHour),
+		})
 	}
 
-	sentMessage, err := app.bot.SendMessage(conversation.ChatId, request)
-	if err != nil {
-		app.logger.Println("ERROR! Unable to send listing message:", err)
-		return
+	return append(keyboard, row)
+}
+
+func (app *TelegramBotApp) getSettingEmoji(isEnabled bool) string {
+	if isEnabled {
+		return string(telegram.EmojiWhiteCheckMark)
 	}
 
-	conversation.StoreContext(telegram.ConversationCtxMessage, sentMessage)
+	return string(telegram.EmojiX)
 }
 
 // Create page navigation inline keyboard.
@@ -757,8 +2352,13 @@ func (app *TelegramBotApp) confirmMarketplaceProductDelete(conversation *telegra
 		return
 	}
 
+	if !app.checkDeleteAllowed(conversation) {
+		conversation.Reset()
+		return
+	}
+
 	slug := strings.Replace(conversation.LastMessage.Text, telegram.CommandPrefixDeleteProduct, "", 1)
-	conversation.StoreContext(telegram.ConversationCtxProductSlug, slug)
+	conversation.Data.ProductSlug = slug
 
 	model, err := app.findUserProductBySlug(conversation.ChatId, conversation.LastMessage.From.Id, slug)
 	if err != nil {
@@ -805,7 +2405,12 @@ func (app *TelegramBotApp) confirmMarketplaceProductDelete(conversation *telegra
 
 // Delete marketplace product.
 func (app *TelegramBotApp) deleteMarketplaceProduct(conversation *telegram.Conversation) {
-	productSlug := conversation.GetContext(telegram.ConversationCtxProductSlug).(string)
+	if !app.checkDeleteAllowed(conversation) {
+		conversation.Reset()
+		return
+	}
+
+	productSlug := conversation.Data.ProductSlug
 
 	product, err := app.findUserProductBySlug(conversation.ChatId, conversation.LastMessage.From.Id, productSlug)
 	if err != nil {
@@ -838,14 +2443,93 @@ func (app *TelegramBotApp) deleteMarketplaceProduct(conversation *telegram.Conve
 	conversation.Reset()
 }
 
-// Check if conversation context type is "TrackedProduct".
-func (app *TelegramBotApp) isTrackedProductContext(conversation *telegram.Conversation) bool {
-	return reflect.TypeOf(conversation.GetContext(telegram.ConversationCtxProduct)) == reflect.TypeOf(TrackedProduct{})
+// Check if user is allowed to delete products: only administrators can delete products of shared watchlist.
+// User is told about it, if deletion is not allowed.
+func (app *TelegramBotApp) checkDeleteAllowed(conversation *telegram.Conversation) bool {
+	if !app.marketplaceService.IsSharedWatchlist(conversation.ChatId) || app.isChatAdmin(conversation) {
+		return true
+	}
+
+	app.bot.AnswerCallbackQuery(conversation.LastCallbackQueryId)
+	app.bot.SendMessage(conversation.ChatId, telegram.SendMessageRequest{
+		ReplyToMessageId: conversation.LastMessage.MessageId,
+		Text:             "Удалять товары из общего списка могут только администраторы чата",
+	})
+
+	return false
+}
+
+// Check if user is allowed to change product: only administrators and the member who added it can change product of shared watchlist.
+// User is told about it, if change is not allowed.
+func (app *TelegramBotApp) checkChangeAllowed(conversation *telegram.Conversation, product marketplace.Product) bool {
+	if product.TelegramUserId == conversation.User.Id || !app.marketplaceService.IsSharedWatchlist(conversation.ChatId) || app.isChatAdmin(conversation) {
+		return true
+	}
+
+	app.bot.SendMessage(conversation.ChatId, telegram.SendMessageRequest{
+		ReplyToMessageId: conversation.LastMessage.MessageId,
+		Text:             "Изменять товары общего списка могут только администраторы чата и участник, который добавил товар",
+	})
+
+	return false
+}
+
+// Check if user is administrator of group chat.
+func (app *TelegramBotApp) isChatAdmin(conversation *telegram.Conversation) bool {
+	member, err := app.bot.GetChatMember(conversation.ChatId, conversation.User.Id)
+	if err != nil {
+		app.logger.Println("ERROR! Unable to get chat member:", err)
+		return false
+	}
+
+	return member.IsAdmin()
+}
+
+// Share products of group with all its members (or stop sharing), only administrators are allowed to do it.
+func (app *TelegramBotApp) toggleSharedWatchlist(conversation *telegram.Conversation) {
+	request := telegram.SendMessageRequest{
+		ReplyToMessageId: conversation.LastMessage.MessageId,
+	}
+
+	if !conversation.LastMessage.Chat.IsGroup() {
+		request.Text = "Общий список товаров доступен только в группах"
+		app.bot.SendMessage(conversation.ChatId, request)
+		return
+	}
+
+	if !app.isChatAdmin(conversation) {
+		request.Text = "Изменить общий список товаров могут только администраторы чата"
+		app.bot.SendMessage(conversation.ChatId, request)
+		return
+	}
+
+	isShared := !app.marketplaceService.IsSharedWatchlist(conversation.ChatId)
+
+	if err := app.marketplaceService.SetSharedWatchlist(conversation.ChatId, isShared); err != nil {
+		app.logErrorAndSendMessage(conversation, err, "Unable to change shared watchlist", "Не могу изменить общий список товаров")
+		return
+	}
+
+	if isShared {
+		request.Text = helpers.ConcatStrings(
+			"Теперь список товаров общий для всех участников чата ", string(telegram.EmojiOkHand), "\n\n",
+			"Добавлять товары может любой участник, а удалять — только администраторы\n",
+			"Изменять товар могут администраторы и участник, который его добавил",
+		)
+	} else {
+		request.Text = helpers.ConcatStrings(
+			"Теперь у каждого участника чата свой список товаров ", string(telegram.EmojiOkHand),
+		)
+	}
+
+	app.bot.SendMessage(conversation.ChatId, request)
 }
 
-// Find user's saved product by URL.
+// Find user's saved product by URL (any product of chat, if watchlist is shared).
 func (app *TelegramBotApp) findUserProductByUrl(telegramChatId int, telegramUserId int, url string) (marketplace.Product, error) {
-	model, err := app.marketplaceService.FindForUserByUrl(telegramChatId, telegramUserId, url)
+	ownerId := app.marketplaceService.GetWatchlistOwnerId(telegramChatId, telegramUserId)
+
+	model, err := app.marketplaceService.FindForUserByUrl(telegramChatId, ownerId, url)
 	if err != nil {
 		return marketplace.Product{}, err
 	}
@@ -853,9 +2537,11 @@ func (app *TelegramBotApp) findUserProductByUrl(telegramChatId int, telegramUser
 	return model, nil
 }
 
-// Find user's saved product by slug.
+// Find user's saved product by slug (any product of chat, if watchlist is shared).
 func (app *TelegramBotApp) findUserProductBySlug(telegramChatId int, telegramUserId int, slug string) (marketplace.Product, error) {
-	model, err := app.marketplaceService.FindForUserBySlug(telegramChatId, telegramUserId, slug)
+	ownerId := app.marketplaceService.GetWatchlistOwnerId(telegramChatId, telegramUserId)
+
+	model, err := app.marketplaceService.FindForUserBySlug(telegramChatId, ownerId, slug)
 	if err != nil {
 		return marketplace.Product{}, err
 	}
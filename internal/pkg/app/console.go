@@ -2,6 +2,9 @@ package app
 
 import (
 	"bot/internal/app/database"
+	"bot/internal/app/helpers"
+	"bot/internal/app/logger"
+	"bot/internal/app/notification"
 	"fmt"
 	"os"
 	"strings"
@@ -61,7 +64,51 @@ func (app ConsoleApp) listenToCommands() {
 		}
 
 		database.CreateNewMigration(strings.TrimSpace(args[2]))
+	case "notifications:status":
+		app.showNotificationsStatus()
 	default:
 		fmt.Printf("Unknown command \"%s\"\n", command)
 	}
 }
+
+// Print count of notifications by delivery status and the latest undelivered ones.
+func (app ConsoleApp) showNotificationsStatus() {
+	const deadLimit = 20
+
+	repository := notification.NewPostgresNotificationRepository(app.db, logger.NewFileLogger("console.log", false))
+
+	counts, err := repository.GetCountByStatus()
+	if err != nil {
+		fmt.Println("Error:", err)
+		return
+	}
+
+	for _, status := range []notification.NotificationStatus{
+		notification.NotificationStatusPending,
+		notification.NotificationStatusSent,
+		notification.NotificationStatusDead,
+	} {
+		fmt.Printf("%-10s %d\n", status, counts[status])
+	}
+
+	dead := repository.FindDead(deadLimit)
+
+	if len(dead) == 0 {
+		return
+	}
+
+	fmt.Println()
+	fmt.Println("Latest dead notifications:")
+
+	for _, model := range dead {
+		fmt.Printf(
+			"#%d %s chat %d, product %d, attempts %d: %s\n",
+			model.Id,
+			helpers.TimeToDatabase(model.CreatedAt),
+			model.TelegramChatId,
+			model.ProductId,
+			model.Attempts,
+			model.Error,
+		)
+	}
+}
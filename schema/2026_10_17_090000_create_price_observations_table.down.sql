@@ -0,0 +1 @@
+DROP TABLE price_observations;
\ No newline at end of file
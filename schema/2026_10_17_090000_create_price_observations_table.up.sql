@@ -0,0 +1,10 @@
+CREATE TABLE price_observations (
+    id SERIAL PRIMARY KEY,
+    product_id INTEGER NOT NULL REFERENCES products (id) ON DELETE CASCADE,
+    marketplace SMALLINT NOT NULL,
+    price INTEGER NOT NULL DEFAULT 0,
+    out_of_stock BOOLEAN DEFAULT FALSE,
+    scraped_at TIMESTAMP(0) NOT NULL
+);
+
+CREATE INDEX idx_price_observations_product_scraped_at ON price_observations (product_id, scraped_at);
\ No newline at end of file
@@ -0,0 +1 @@
+ALTER TABLE products DROP COLUMN target_price;
\ No newline at end of file
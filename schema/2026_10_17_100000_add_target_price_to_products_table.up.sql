@@ -0,0 +1 @@
+ALTER TABLE products ADD COLUMN target_price INTEGER NOT NULL DEFAULT 0;
\ No newline at end of file
@@ -0,0 +1 @@
+ALTER TABLE price_observations DROP COLUMN scrape_method;
\ No newline at end of file
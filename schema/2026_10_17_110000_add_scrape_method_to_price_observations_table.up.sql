@@ -0,0 +1 @@
+ALTER TABLE price_observations ADD COLUMN scrape_method SMALLINT NOT NULL DEFAULT 0;
\ No newline at end of file
@@ -0,0 +1 @@
+DROP TABLE telegram_updates;
\ No newline at end of file
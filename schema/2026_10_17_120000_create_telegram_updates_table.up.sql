@@ -0,0 +1,8 @@
+CREATE TABLE telegram_updates (
+    update_id BIGINT PRIMARY KEY,
+    payload JSONB NOT NULL,
+    received_at TIMESTAMP(0) NOT NULL,
+    processed_at TIMESTAMP(0) DEFAULT NULL
+);
+
+CREATE INDEX idx_unprocessed_updates ON telegram_updates (update_id) WHERE processed_at IS NULL;
\ No newline at end of file
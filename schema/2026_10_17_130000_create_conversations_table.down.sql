@@ -0,0 +1 @@
+DROP TABLE conversations;
\ No newline at end of file
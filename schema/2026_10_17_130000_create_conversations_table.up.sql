@@ -0,0 +1,12 @@
+CREATE TABLE conversations (
+    hash VARCHAR(32) PRIMARY KEY,
+    chat_id BIGINT NOT NULL,
+    from_user JSONB NOT NULL,
+    last_message JSONB NOT NULL,
+    last_callback_query_id VARCHAR(255) NOT NULL DEFAULT '',
+    state VARCHAR(64) NOT NULL DEFAULT '',
+    data JSONB NOT NULL,
+    updated_at TIMESTAMP(0) NOT NULL
+);
+
+CREATE INDEX idx_conversations_updated_at ON conversations (updated_at);
\ No newline at end of file
@@ -0,0 +1,14 @@
+CREATE TABLE scrape_jobs (
+    id SERIAL PRIMARY KEY,
+    url VARCHAR(2048) NOT NULL,
+    telegram_chat_id BIGINT NOT NULL,
+    telegram_user_id BIGINT NOT NULL,
+    telegram_message_id BIGINT NOT NULL DEFAULT 0,
+    status VARCHAR(16) NOT NULL DEFAULT 'pending',
+    error TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP(0) NOT NULL,
+    started_at TIMESTAMP(0) DEFAULT NULL,
+    finished_at TIMESTAMP(0) DEFAULT NULL
+);
+
+CREATE INDEX idx_scrape_jobs_status ON scrape_jobs (status, id);
\ No newline at end of file
@@ -0,0 +1 @@
+ALTER TABLE products DROP COLUMN is_chat_blocked;
\ No newline at end of file
@@ -0,0 +1 @@
+ALTER TABLE products ADD COLUMN is_chat_blocked BOOLEAN NOT NULL DEFAULT FALSE;
\ No newline at end of file
@@ -0,0 +1 @@
+ALTER TABLE products DROP COLUMN is_active;
\ No newline at end of file
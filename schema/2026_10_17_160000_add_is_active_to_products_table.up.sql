@@ -0,0 +1 @@
+ALTER TABLE products ADD COLUMN is_active BOOLEAN NOT NULL DEFAULT TRUE;
\ No newline at end of file
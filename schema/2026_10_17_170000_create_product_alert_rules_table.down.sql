@@ -0,0 +1 @@
+DROP TABLE product_alert_rules;
\ No newline at end of file
@@ -0,0 +1,9 @@
+CREATE TABLE product_alert_rules (
+    id SERIAL PRIMARY KEY,
+    product_id INTEGER NOT NULL REFERENCES products (id) ON DELETE CASCADE,
+    type VARCHAR(32) NOT NULL,
+    value INTEGER NOT NULL DEFAULT 0,
+    created_at TIMESTAMP(0) NOT NULL
+);
+
+CREATE INDEX idx_product_alert_rules_product_id ON product_alert_rules (product_id);
\ No newline at end of file
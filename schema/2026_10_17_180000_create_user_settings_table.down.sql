@@ -0,0 +1 @@
+DROP TABLE user_settings;
\ No newline at end of file
@@ -0,0 +1,12 @@
+CREATE TABLE user_settings (
+    id SERIAL PRIMARY KEY,
+    telegram_chat_id BIGINT NOT NULL,
+    telegram_user_id BIGINT NOT NULL,
+    notify_price_increase BOOLEAN NOT NULL DEFAULT FALSE,
+    notify_sold_out BOOLEAN NOT NULL DEFAULT FALSE,
+    unchanged_digest_days SMALLINT NOT NULL DEFAULT 0,
+    unchanged_digest_sent_at TIMESTAMP(0) DEFAULT NULL,
+    updated_at TIMESTAMP(0) NOT NULL
+);
+
+CREATE UNIQUE INDEX idx_user_settings_chat_user ON user_settings (telegram_chat_id, telegram_user_id);
\ No newline at end of file
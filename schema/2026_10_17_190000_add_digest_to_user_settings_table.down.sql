@@ -0,0 +1,5 @@
+ALTER TABLE user_settings
+    DROP COLUMN digest_mode,
+    DROP COLUMN digest_hour,
+    DROP COLUMN timezone,
+    DROP COLUMN digest_sent_at;
\ No newline at end of file
@@ -0,0 +1,5 @@
+ALTER TABLE user_settings
+    ADD COLUMN digest_mode VARCHAR NOT NULL DEFAULT '',
+    ADD COLUMN digest_hour SMALLINT NOT NULL DEFAULT 9,
+    ADD COLUMN timezone VARCHAR NOT NULL DEFAULT '',
+    ADD COLUMN digest_sent_at TIMESTAMP(0) DEFAULT NULL;
\ No newline at end of file
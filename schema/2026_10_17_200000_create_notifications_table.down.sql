@@ -0,0 +1 @@
+DROP TABLE notifications;
\ No newline at end of file
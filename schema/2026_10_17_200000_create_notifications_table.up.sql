@@ -0,0 +1,19 @@
+CREATE TABLE notifications (
+    id SERIAL PRIMARY KEY,
+    telegram_chat_id BIGINT NOT NULL,
+    telegram_user_id BIGINT NOT NULL,
+    product_id INTEGER NOT NULL REFERENCES products (id) ON DELETE CASCADE,
+    url VARCHAR NOT NULL,
+    marketplace SMALLINT NOT NULL,
+    title VARCHAR NOT NULL,
+    old_price INTEGER NOT NULL DEFAULT 0,
+    new_price INTEGER NOT NULL DEFAULT 0,
+    target_price INTEGER NOT NULL DEFAULT 0,
+    out_of_stock BOOLEAN NOT NULL DEFAULT FALSE,
+    alerts JSONB NOT NULL DEFAULT '[]',
+    event VARCHAR NOT NULL DEFAULT '',
+    created_at TIMESTAMP(0) NOT NULL,
+    sent_at TIMESTAMP(0) DEFAULT NULL
+);
+
+CREATE INDEX idx_notifications_pending ON notifications (telegram_chat_id, telegram_user_id) WHERE sent_at IS NULL;
\ No newline at end of file
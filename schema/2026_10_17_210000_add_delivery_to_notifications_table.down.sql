@@ -0,0 +1,5 @@
+ALTER TABLE notifications
+    DROP COLUMN status,
+    DROP COLUMN attempts,
+    DROP COLUMN next_attempt_at,
+    DROP COLUMN error;
\ No newline at end of file
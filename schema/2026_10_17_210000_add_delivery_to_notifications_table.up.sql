@@ -0,0 +1,9 @@
+ALTER TABLE notifications
+    ADD COLUMN status VARCHAR NOT NULL DEFAULT 'pending',
+    ADD COLUMN attempts SMALLINT NOT NULL DEFAULT 0,
+    ADD COLUMN next_attempt_at TIMESTAMP(0) NOT NULL DEFAULT NOW(),
+    ADD COLUMN error VARCHAR NOT NULL DEFAULT '';
+
+UPDATE notifications SET status = 'sent' WHERE sent_at IS NOT NULL;
+
+CREATE INDEX idx_notifications_due ON notifications (next_attempt_at) WHERE status = 'pending';
\ No newline at end of file
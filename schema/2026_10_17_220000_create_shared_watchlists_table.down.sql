@@ -0,0 +1 @@
+DROP TABLE shared_watchlists;
\ No newline at end of file
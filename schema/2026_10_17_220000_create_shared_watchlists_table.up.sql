@@ -0,0 +1,5 @@
+CREATE TABLE shared_watchlists (
+    id SERIAL PRIMARY KEY,
+    telegram_chat_id BIGINT NOT NULL UNIQUE,
+    created_at TIMESTAMP(0) NOT NULL
+);
\ No newline at end of file
@@ -0,0 +1 @@
+ALTER TABLE products DROP COLUMN telegram_user_name;
\ No newline at end of file
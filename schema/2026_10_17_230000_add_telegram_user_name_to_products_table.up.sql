@@ -0,0 +1 @@
+ALTER TABLE products ADD COLUMN telegram_user_name VARCHAR NOT NULL DEFAULT '';
\ No newline at end of file
@@ -0,0 +1 @@
+ALTER TABLE notifications DROP COLUMN telegram_user_name;
\ No newline at end of file
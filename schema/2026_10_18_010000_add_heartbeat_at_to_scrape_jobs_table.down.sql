@@ -0,0 +1 @@
+ALTER TABLE scrape_jobs DROP COLUMN heartbeat_at;
\ No newline at end of file
@@ -0,0 +1,3 @@
+ALTER TABLE scrape_jobs ADD COLUMN heartbeat_at TIMESTAMP(0) DEFAULT NULL;
+
+UPDATE scrape_jobs SET heartbeat_at = started_at WHERE status = 'running';
\ No newline at end of file